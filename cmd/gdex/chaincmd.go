@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -25,12 +26,15 @@ import (
 	"sync/atomic"
 	"time"
 
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
+
 	"github.com/dexon-foundation/dexon/cmd/utils"
 	"github.com/dexon-foundation/dexon/common"
 	"github.com/dexon-foundation/dexon/console"
 	"github.com/dexon-foundation/dexon/core"
 	"github.com/dexon-foundation/dexon/core/state"
 	"github.com/dexon-foundation/dexon/core/types"
+	"github.com/dexon-foundation/dexon/dex"
 	"github.com/dexon-foundation/dexon/eth/downloader"
 	"github.com/dexon-foundation/dexon/ethdb"
 	"github.com/dexon-foundation/dexon/event"
@@ -151,6 +155,23 @@ The first argument must be the directory containing the blockchain to download f
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 Remove blockchain and state databases`,
+	}
+	verifyHistoryCommand = cli.Command{
+		Action:    utils.MigrateFlags(verifyHistory),
+		Name:      "verify-history",
+		Usage:     "Verify finalized blocks in storage",
+		ArgsUsage: "[<fromBlockNum>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Replay finalized blocks from the given block number, or the first block, to the
+current block through the consensus verification rules: block hashes and
+signatures, notary sets, randomness signed by DKG sets and chaining of blocks.
+It fails at the first invalid block.`,
 	}
 	dumpCommand = cli.Command{
 		Action:    utils.MigrateFlags(dump),
@@ -464,6 +485,46 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+func verifyHistory(ctx *cli.Context) error {
+	var from uint64
+	if len(ctx.Args()) > 0 {
+		num, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+		if err != nil {
+			utils.Fatalf("Invalid block number: %v", err)
+		}
+		from = num
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	verifier := dexCore.NewHistoryVerifier(dex.NewHistoryGovernance(chain),
+		dex.NewChainHistory(chain), log.Root())
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(8 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p := verifier.Progress()
+				log.Info("Verifying history", "verified", p.Verified,
+					"from", p.From, "to", p.To, "round", p.Round,
+					"elapsed", common.PrettyDuration(p.Elapsed))
+			}
+		}
+	}()
+	if err := verifier.Verify(context.Background(), from); err != nil {
+		utils.Fatalf("History verification failed: %v", err)
+	}
+	p := verifier.Progress()
+	fmt.Printf("Verified %d blocks in %v\n", p.Verified, p.Elapsed)
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
@@ -98,6 +98,10 @@ var (
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
 		utils.BlockProposerEnabledFlag,
+		utils.BlockPropagationFlag,
+		utils.VoteRetentionFlag,
+		utils.NotaryPeerLimitFlag,
+		utils.NotaryPeerMarginFlag,
 		utils.MiningEnabledFlag,
 		utils.MinerThreadsFlag,
 		utils.MinerLegacyThreadsFlag,
@@ -119,6 +123,7 @@ var (
 		utils.NetrestrictFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
+		utils.NodeKeyStoreFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
 		utils.TestnetFlag,
@@ -191,6 +196,7 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		verifyHistoryCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
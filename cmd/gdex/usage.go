@@ -183,12 +183,17 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.NetrestrictFlag,
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
+			utils.NodeKeyStoreFlag,
 		},
 	},
 	{
 		Name: "BLOCK PROPOSER",
 		Flags: []cli.Flag{
 			utils.BlockProposerEnabledFlag,
+			utils.BlockPropagationFlag,
+			utils.VoteRetentionFlag,
+			utils.NotaryPeerLimitFlag,
+			utils.NotaryPeerMarginFlag,
 		},
 	},
 	{
@@ -3,9 +3,14 @@ package main
 import (
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+
 	"github.com/dexon-foundation/dexon/cmd/utils"
+	"github.com/dexon-foundation/dexon/console"
 	"github.com/dexon-foundation/dexon/crypto"
 
 	"gopkg.in/urfave/cli.v1"
@@ -25,6 +30,12 @@ func init() {
 	app.Commands = []cli.Command{
 		commandGenerate,
 		commandInspect,
+		commandImport,
+		commandPasswd,
+	}
+	app.Flags = []cli.Flag{
+		utils.PasswordFileFlag,
+		utils.LightKDFFlag,
 	}
 }
 
@@ -85,6 +96,107 @@ var commandInspect = cli.Command{
 	},
 }
 
+var commandImport = cli.Command{
+	Name:      "import",
+	Usage:     "encrypt a keyfile",
+	ArgsUsage: "<keyfile> <encrypted keyfile>",
+	Description: `Encrypt a node key with a passphrase, into the format loaded by
+the --nodekeystore option of gdex. The passphrase is prompted, or the first
+line of --password.`,
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) != 2 {
+			utils.Fatalf("Keyfile and encrypted keyfile must be given.")
+		}
+		keyfilepath, outpath := ctx.Args().Get(0), ctx.Args().Get(1)
+		if _, err := os.Stat(outpath); err == nil {
+			utils.Fatalf("Encrypted keyfile already exists at %s.", outpath)
+		} else if !os.IsNotExist(err) {
+			utils.Fatalf("Error checking if encrypted keyfile exists: %v", err)
+		}
+
+		privKey, err := crypto.LoadECDSA(keyfilepath)
+		if err != nil {
+			utils.Fatalf("Failed to read key file: %v", err)
+		}
+		passphrase := getPassphrase(ctx, 0, true)
+		argon2Time, argon2Memory := kdfParams(ctx)
+		data, err := keystore.ExportECDSA(
+			coreEcdsa.NewPrivateKeyFromECDSA(privKey), passphrase,
+			argon2Time, argon2Memory)
+		if err != nil {
+			utils.Fatalf("Failed to encrypt key: %v", err)
+		}
+		if err := ioutil.WriteFile(outpath, data, 0600); err != nil {
+			utils.Fatalf("Failed to save encrypted keyfile: %v", err)
+		}
+
+		address := crypto.PubkeyToAddress(privKey.PublicKey)
+		fmt.Printf("Node Address: %s\n", address.String())
+		return nil
+	},
+}
+
+var commandPasswd = cli.Command{
+	Name:      "passwd",
+	Usage:     "change the passphrase of an encrypted keyfile",
+	ArgsUsage: "<encrypted keyfile>",
+	Description: `Re-encrypt a node key with a new passphrase. Passphrases are
+prompted, or the first two lines of --password.`,
+	Action: func(ctx *cli.Context) error {
+		keyfilepath := ctx.Args().First()
+
+		data, err := ioutil.ReadFile(keyfilepath)
+		if err != nil {
+			utils.Fatalf("Failed to read encrypted key file: %v", err)
+		}
+		passphrase := getPassphrase(ctx, 0, false)
+		newPassphrase := getPassphrase(ctx, 1, true)
+		argon2Time, argon2Memory := kdfParams(ctx)
+		data, err = keystore.ChangePassphrase(
+			data, passphrase, newPassphrase, argon2Time, argon2Memory)
+		if err != nil {
+			utils.Fatalf("Failed to change passphrase: %v", err)
+		}
+		if err := ioutil.WriteFile(keyfilepath, data, 0600); err != nil {
+			utils.Fatalf("Failed to save encrypted keyfile: %v", err)
+		}
+		return nil
+	},
+}
+
+// getPassphrase returns the i-th line of --password, or prompts for it.
+func getPassphrase(ctx *cli.Context, i int, confirmation bool) string {
+	if passwords := utils.MakePasswordList(ctx); len(passwords) > i {
+		return passwords[i]
+	}
+	prompt := "Passphrase: "
+	if i > 0 {
+		prompt = "New passphrase: "
+	}
+	passphrase, err := console.Stdin.PromptPassword(prompt)
+	if err != nil {
+		utils.Fatalf("Failed to read passphrase: %v", err)
+	}
+	if confirmation {
+		confirm, err := console.Stdin.PromptPassword("Repeat passphrase: ")
+		if err != nil {
+			utils.Fatalf("Failed to read passphrase confirmation: %v", err)
+		}
+		if passphrase != confirm {
+			utils.Fatalf("Passphrases do not match")
+		}
+	}
+	return passphrase
+}
+
+// kdfParams returns the argon2id parameters to encrypt with.
+func kdfParams(ctx *cli.Context) (uint32, uint32) {
+	if ctx.GlobalBool(utils.LightKDFFlag.Name) {
+		return keystore.LightArgon2Time, keystore.LightArgon2Memory
+	}
+	return keystore.StandardArgon2Time, keystore.StandardArgon2Memory
+}
+
 func main() {
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
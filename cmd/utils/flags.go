@@ -28,6 +28,9 @@ import (
 	"strings"
 	"time"
 
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	corekeystore "github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+
 	"github.com/dexon-foundation/dexon/accounts"
 	"github.com/dexon-foundation/dexon/accounts/keystore"
 	"github.com/dexon-foundation/dexon/common"
@@ -35,6 +38,7 @@ import (
 	"github.com/dexon-foundation/dexon/consensus"
 	"github.com/dexon-foundation/dexon/consensus/clique"
 	"github.com/dexon-foundation/dexon/consensus/ethash"
+	"github.com/dexon-foundation/dexon/console"
 	"github.com/dexon-foundation/dexon/core"
 	"github.com/dexon-foundation/dexon/core/state"
 	"github.com/dexon-foundation/dexon/core/vm"
@@ -330,6 +334,25 @@ var (
 		Name:  "bp",
 		Usage: "Enable block proposer mode (node set)",
 	}
+	BlockPropagationFlag = cli.StringFlag{
+		Name:  "bp.propagation",
+		Usage: `Strategy to propagate core blocks ("push" or "push-pull")`,
+		Value: string(dex.PushPropagation),
+	}
+	VoteRetentionFlag = cli.Uint64Flag{
+		Name:  "bp.voteretention",
+		Usage: "Number of heights behind the latest finalized block to keep votes for peers (0 = until evicted)",
+		Value: dex.DefaultConfig.VoteRetention,
+	}
+	NotaryPeerLimitFlag = cli.BoolFlag{
+		Name:  "bp.notarypeerlimit",
+		Usage: "Derive the peer limit from notary set sizes instead of --maxpeers",
+	}
+	NotaryPeerMarginFlag = cli.IntFlag{
+		Name:  "bp.notarypeermargin",
+		Usage: "Number of peers allowed beyond the notary set size with --bp.notarypeerlimit",
+		Value: dex.DefaultConfig.NotaryPeerMargin,
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -544,6 +567,10 @@ var (
 		Name:  "nodekeyhex",
 		Usage: "P2P node key as hex (for testing)",
 	}
+	NodeKeyStoreFlag = cli.StringFlag{
+		Name:  "nodekeystore",
+		Usage: "P2P node key file encrypted by 'nodekey import' (unlocked by --password)",
+	}
 	NATFlag = cli.StringFlag{
 		Name:  "nat",
 		Usage: "NAT port mapping mechanism (any|none|upnp|pmp|extip:<IP>)",
@@ -694,18 +721,27 @@ func MakeDataDir(ctx *cli.Context) string {
 }
 
 // setNodeKey creates a node key from set command line flags, either loading it
-// from a file or as a specified hex value. If neither flags were provided, this
-// method returns nil and an emphemeral key is to be generated.
+// from a file, decrypting it from an encrypted file or as a specified hex value.
+// If neither flags were provided, this method returns nil and an emphemeral key
+// is to be generated.
 func setNodeKey(ctx *cli.Context, cfg *p2p.Config) {
 	var (
-		hex  = ctx.GlobalString(NodeKeyHexFlag.Name)
-		file = ctx.GlobalString(NodeKeyFileFlag.Name)
-		key  *ecdsa.PrivateKey
-		err  error
+		hex   = ctx.GlobalString(NodeKeyHexFlag.Name)
+		file  = ctx.GlobalString(NodeKeyFileFlag.Name)
+		store = ctx.GlobalString(NodeKeyStoreFlag.Name)
+		key   *ecdsa.PrivateKey
+		err   error
 	)
 	switch {
 	case file != "" && hex != "":
 		Fatalf("Options %q and %q are mutually exclusive", NodeKeyFileFlag.Name, NodeKeyHexFlag.Name)
+	case store != "" && (file != "" || hex != ""):
+		Fatalf("Option %q is exclusive with %q and %q", NodeKeyStoreFlag.Name, NodeKeyFileFlag.Name, NodeKeyHexFlag.Name)
+	case store != "":
+		if key, err = loadNodeKeyStore(ctx, store); err != nil {
+			Fatalf("Option %q: %v", NodeKeyStoreFlag.Name, err)
+		}
+		cfg.PrivateKey = key
 	case file != "":
 		if key, err = crypto.LoadECDSA(file); err != nil {
 			Fatalf("Option %q: %v", NodeKeyFileFlag.Name, err)
@@ -719,6 +755,44 @@ func setNodeKey(ctx *cli.Context, cfg *p2p.Config) {
 	}
 }
 
+// loadNodeKeyStore decrypts the node key from an encrypted key file, with the
+// first password of --password, or the one prompted. The current key of a key
+// rotation is loaded, the node is expected to be restarted with it after the
+// grace window.
+func loadNodeKeyStore(ctx *cli.Context, file string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var passphrase string
+	if passwords := MakePasswordList(ctx); len(passwords) > 0 {
+		passphrase = passwords[0]
+	} else {
+		fmt.Printf("Unlocking node key %s\n", file)
+		if passphrase, err = console.Stdin.PromptPassword("Passphrase: "); err != nil {
+			return nil, err
+		}
+	}
+	kind, err := corekeystore.Kind(data)
+	if err != nil {
+		return nil, err
+	}
+	var key *coreEcdsa.PrivateKey
+	switch kind {
+	case corekeystore.KindRotation:
+		rotation, err := corekeystore.ImportRotation(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		key = rotation.Current
+	default:
+		if key, err = corekeystore.ImportECDSA(data, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return crypto.ToECDSA(key.Bytes())
+}
+
 // setNodeUserIdent creates the user identifier from CLI flags.
 func setNodeUserIdent(ctx *cli.Context, cfg *node.Config) {
 	if identity := ctx.GlobalString(IdentityFlag.Name); len(identity) > 0 {
@@ -1229,6 +1303,24 @@ func SetDexConfig(ctx *cli.Context, stack *node.Node, cfg *dex.Config) {
 	if ctx.GlobalIsSet(BlockProposerEnabledFlag.Name) {
 		cfg.BlockProposerEnabled = ctx.GlobalBool(BlockProposerEnabledFlag.Name)
 	}
+	if ctx.GlobalIsSet(BlockPropagationFlag.Name) {
+		cfg.BlockPropagation = dex.BlockPropagation(ctx.GlobalString(BlockPropagationFlag.Name))
+		if !cfg.BlockPropagation.IsValid() {
+			Fatalf("--%s must be either 'push' or 'push-pull'", BlockPropagationFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(VoteRetentionFlag.Name) {
+		cfg.VoteRetention = ctx.GlobalUint64(VoteRetentionFlag.Name)
+	}
+	if ctx.GlobalIsSet(NotaryPeerLimitFlag.Name) {
+		cfg.NotaryPeerLimit = ctx.GlobalBool(NotaryPeerLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(NotaryPeerMarginFlag.Name) {
+		cfg.NotaryPeerMargin = ctx.GlobalInt(NotaryPeerMarginFlag.Name)
+		if cfg.NotaryPeerMargin < 0 {
+			Fatalf("--%s must not be negative", NotaryPeerMarginFlag.Name)
+		}
+	}
 
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheDatabaseFlag.Name) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
@@ -21,6 +21,7 @@ import (
 	"github.com/dexon-foundation/dexon/core/vm"
 	"github.com/dexon-foundation/dexon/crypto"
 	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/params"
 )
 
 const dkgCacheSize = 5
@@ -326,3 +327,24 @@ func (g *Governance) MinGasPrice(round uint64) *big.Int {
 func (g *Governance) DKGResetCount(round uint64) uint64 {
 	return g.GetHeadState().DKGResetCount(big.NewInt(int64(round))).Uint64()
 }
+
+// ManualFinalizationOperators returns node IDs of operators authorizing
+// manual finalization in 'config', and how many of them should sign.
+func ManualFinalizationOperators(
+	config *params.ChainConfig) (coreTypes.NodeIDs, int) {
+	if config == nil || config.ManualFinalization == nil {
+		return nil, 0
+	}
+	keys := config.ManualFinalization.Operators
+	operators := make(coreTypes.NodeIDs, 0, len(keys))
+	for _, key := range keys {
+		pk, err := coreEcdsa.NewPublicKeyFromByteSlice(key)
+		if err != nil {
+			log.Error("Invalid manual finalization operator",
+				"key", key, "err", err)
+			continue
+		}
+		operators = append(operators, coreTypes.NewNodeID(pk))
+	}
+	return operators, config.ManualFinalization.Threshold
+}
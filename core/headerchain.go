@@ -631,6 +631,17 @@ func (hc *HeaderChain) verifyDexonHeader(header *types.Header,
 	return nil
 }
 
+// manualFinalizationGovernance defines operators of manual finalization by
+// the chain config.
+type manualFinalizationGovernance struct {
+	config *params.ChainConfig
+}
+
+func (g manualFinalizationGovernance) ManualFinalizationOperators(
+	round uint64) (coreTypes.NodeIDs, int) {
+	return ManualFinalizationOperators(g.config)
+}
+
 func (hc *HeaderChain) verifyTSig(coreBlock *coreTypes.Block,
 	verifierCache *dexCore.TSigVerifierCache) error {
 
@@ -641,6 +652,12 @@ func (hc *HeaderChain) verifyTSig(coreBlock *coreTypes.Block,
 		return nil
 	}
 
+	if coreTypes.IsManuallyFinalized(coreBlock) {
+		return dexCore.VerifyManualFinalization(
+			manualFinalizationGovernance{hc.config}, coreBlock.Position,
+			coreBlock.Hash, coreBlock.IsEmpty(), randomness)
+	}
+
 	// Verify threshold signature
 	v, ok, err := verifierCache.UpdateAndGet(round)
 	if err != nil {
@@ -0,0 +1,44 @@
+package rawdb
+
+import (
+	"bytes"
+
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+func ReadCoreAgreementCheckpointRLP(db DatabaseReader) rlp.RawValue {
+	data, _ := db.Get(coreAgreementCheckpointKey)
+	return data
+}
+
+func WriteCoreAgreementCheckpointRLP(db DatabaseWriter, rlp rlp.RawValue) error {
+	err := db.Put(coreAgreementCheckpointKey, rlp)
+	if err != nil {
+		log.Crit("Failed to store core agreement checkpoint", "err", err)
+	}
+	return err
+}
+
+func ReadCoreAgreementCheckpoint(db DatabaseReader) *coreTypes.AgreementSnapshot {
+	data := ReadCoreAgreementCheckpointRLP(db)
+	if len(data) == 0 {
+		return nil
+	}
+	s := new(coreTypes.AgreementSnapshot)
+	if err := rlp.Decode(bytes.NewReader(data), s); err != nil {
+		log.Error("Invalid core agreement checkpoint RLP", "err", err)
+		return nil
+	}
+	return s
+}
+
+func WriteCoreAgreementCheckpoint(db DatabaseWriter, s *coreTypes.AgreementSnapshot) error {
+	data, err := rlp.EncodeToBytes(s)
+	if err != nil {
+		log.Crit("Failed to RLP encode core agreement checkpoint", "err", err)
+		return err
+	}
+	return WriteCoreAgreementCheckpointRLP(db, data)
+}
@@ -0,0 +1,44 @@
+package rawdb
+
+import (
+	"bytes"
+
+	coreDb "github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+func ReadCorePendingBARLP(db DatabaseReader) rlp.RawValue {
+	data, _ := db.Get(corePendingBAKey)
+	return data
+}
+
+func WriteCorePendingBARLP(db DatabaseWriter, rlp rlp.RawValue) error {
+	err := db.Put(corePendingBAKey, rlp)
+	if err != nil {
+		log.Crit("Failed to store core pending BA", "err", err)
+	}
+	return err
+}
+
+func ReadCorePendingBA(db DatabaseReader) *coreDb.PendingBAInfo {
+	data := ReadCorePendingBARLP(db)
+	if len(data) == 0 {
+		return nil
+	}
+	info := new(coreDb.PendingBAInfo)
+	if err := rlp.Decode(bytes.NewReader(data), info); err != nil {
+		log.Error("Invalid core pending BA RLP", "err", err)
+		return nil
+	}
+	return info
+}
+
+func WriteCorePendingBA(db DatabaseWriter, info *coreDb.PendingBAInfo) error {
+	data, err := rlp.EncodeToBytes(info)
+	if err != nil {
+		log.Crit("Failed to RLP encode core pending BA", "err", err)
+		return err
+	}
+	return WriteCorePendingBARLP(db, data)
+}
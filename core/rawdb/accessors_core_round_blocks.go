@@ -0,0 +1,44 @@
+package rawdb
+
+import (
+	"bytes"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+func ReadCoreRoundBlocksRLP(db DatabaseReader, round uint64) rlp.RawValue {
+	data, _ := db.Get(coreRoundBlocksKey(round))
+	return data
+}
+
+func WriteCoreRoundBlocksRLP(db DatabaseWriter, round uint64, rlp rlp.RawValue) error {
+	err := db.Put(coreRoundBlocksKey(round), rlp)
+	if err != nil {
+		log.Crit("Failed to store core round blocks", "err", err, "round", round)
+	}
+	return err
+}
+
+func ReadCoreRoundBlocks(db DatabaseReader, round uint64) (coreCommon.Hashes, error) {
+	data := ReadCoreRoundBlocksRLP(db, round)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var hashes coreCommon.Hashes
+	if err := rlp.Decode(bytes.NewReader(data), &hashes); err != nil {
+		log.Error("Invalid core round blocks RLP", "round", round, "err", err)
+		return nil, err
+	}
+	return hashes, nil
+}
+
+func WriteCoreRoundBlocks(db DatabaseWriter, round uint64, hashes coreCommon.Hashes) error {
+	data, err := rlp.EncodeToBytes(hashes)
+	if err != nil {
+		log.Crit("Failed to RLP encode core round blocks", "round", round, "err", err)
+		return err
+	}
+	return WriteCoreRoundBlocksRLP(db, round, data)
+}
@@ -0,0 +1,34 @@
+package rawdb
+
+import (
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+// ReadCoreSchemaVersion retrieves the schema version of consensus data.
+func ReadCoreSchemaVersion(db DatabaseReader) *uint64 {
+	var version uint64
+
+	enc, _ := db.Get(coreSchemaVersionKey)
+	if len(enc) == 0 {
+		return nil
+	}
+	if err := rlp.DecodeBytes(enc, &version); err != nil {
+		log.Error("Invalid core schema version RLP", "err", err)
+		return nil
+	}
+	return &version
+}
+
+// WriteCoreSchemaVersion stores the schema version of consensus data.
+func WriteCoreSchemaVersion(db DatabaseWriter, version uint64) error {
+	enc, err := rlp.EncodeToBytes(version)
+	if err != nil {
+		log.Crit("Failed to RLP encode core schema version", "err", err)
+		return err
+	}
+	if err = db.Put(coreSchemaVersionKey, enc); err != nil {
+		log.Crit("Failed to store core schema version", "err", err)
+	}
+	return err
+}
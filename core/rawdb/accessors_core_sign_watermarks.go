@@ -0,0 +1,44 @@
+package rawdb
+
+import (
+	"bytes"
+
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+func ReadCoreSignWatermarksRLP(db DatabaseReader) rlp.RawValue {
+	data, _ := db.Get(coreSignWatermarksKey)
+	return data
+}
+
+func WriteCoreSignWatermarksRLP(db DatabaseWriter, rlp rlp.RawValue) error {
+	err := db.Put(coreSignWatermarksKey, rlp)
+	if err != nil {
+		log.Crit("Failed to store core sign watermarks", "err", err)
+	}
+	return err
+}
+
+func ReadCoreSignWatermarks(db DatabaseReader) ([]coreTypes.SignWatermark, error) {
+	data := ReadCoreSignWatermarksRLP(db)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var marks []coreTypes.SignWatermark
+	if err := rlp.Decode(bytes.NewReader(data), &marks); err != nil {
+		log.Error("Invalid core sign watermarks RLP", "err", err)
+		return nil, err
+	}
+	return marks, nil
+}
+
+func WriteCoreSignWatermarks(db DatabaseWriter, marks []coreTypes.SignWatermark) error {
+	data, err := rlp.EncodeToBytes(marks)
+	if err != nil {
+		log.Crit("Failed to RLP encode core sign watermarks", "err", err)
+		return err
+	}
+	return WriteCoreSignWatermarksRLP(db, data)
+}
@@ -0,0 +1,44 @@
+package rawdb
+
+import (
+	"bytes"
+
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+func ReadCoreVoteWatermarkRLP(db DatabaseReader) rlp.RawValue {
+	data, _ := db.Get(coreVoteWatermarkKey)
+	return data
+}
+
+func WriteCoreVoteWatermarkRLP(db DatabaseWriter, rlp rlp.RawValue) error {
+	err := db.Put(coreVoteWatermarkKey, rlp)
+	if err != nil {
+		log.Crit("Failed to store core vote watermark", "err", err)
+	}
+	return err
+}
+
+func ReadCoreVoteWatermark(db DatabaseReader) (*coreTypes.Position, error) {
+	data := ReadCoreVoteWatermarkRLP(db)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	pos := new(coreTypes.Position)
+	if err := rlp.Decode(bytes.NewReader(data), pos); err != nil {
+		log.Error("Invalid core vote watermark RLP", "err", err)
+		return nil, err
+	}
+	return pos, nil
+}
+
+func WriteCoreVoteWatermark(db DatabaseWriter, pos coreTypes.Position) error {
+	data, err := rlp.EncodeToBytes(&pos)
+	if err != nil {
+		log.Crit("Failed to RLP encode core vote watermark", "err", err)
+		return err
+	}
+	return WriteCoreVoteWatermarkRLP(db, data)
+}
@@ -55,10 +55,16 @@ var (
 	txLookupPrefix  = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
-	coreBlockPrefix           = []byte("D")
-	coreDKGPrivateKeyPrefix   = []byte("DPK")
-	coreCompactionChainTipKey = []byte("CoreChainTip")
-	coreDKGProtocolKey        = []byte("CoreDKGProtocol")
+	coreBlockPrefix            = []byte("D")
+	coreDKGPrivateKeyPrefix    = []byte("DPK")
+	coreCompactionChainTipKey  = []byte("CoreChainTip")
+	coreDKGProtocolKey         = []byte("CoreDKGProtocol")
+	corePendingBAKey           = []byte("CorePendingBA")
+	coreAgreementCheckpointKey = []byte("CoreAgreementCheckpoint")
+	coreSignWatermarksKey      = []byte("CoreSignWatermarks")
+	coreSchemaVersionKey       = []byte("CoreSchemaVersion")
+	coreVoteWatermarkKey       = []byte("CoreVoteWatermark")
+	coreRoundBlocksPrefix      = []byte("DRB")
 
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
@@ -137,6 +143,14 @@ func coreDKGPrivateKeyKey(round uint64) []byte {
 	return ret
 }
 
+// coreRoundBlocksKey = coreRoundBlocksPrefix + round
+func coreRoundBlocksKey(round uint64) []byte {
+	ret := make([]byte, len(coreRoundBlocksPrefix)+8)
+	copy(ret, coreRoundBlocksPrefix)
+	binary.LittleEndian.PutUint64(ret[len(coreRoundBlocksPrefix):], round)
+	return ret
+}
+
 // bloomBitsKey = bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash
 func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	key := append(append(bloomBitsPrefix, make([]byte, 10)...), hash.Bytes()...)
@@ -2882,3 +2882,13 @@ func PackResetDKG(newSignedCRS []byte) ([]byte, error) {
 	data := append(method.Id(), res...)
 	return data, nil
 }
+
+func PackReplaceNodePublicKey(newPublicKey []byte) ([]byte, error) {
+	method := GovernanceABI.Name2Method["replaceNodePublicKey"]
+	res, err := method.Inputs.Pack(newPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	data := append(method.Id(), res...)
+	return data, nil
+}
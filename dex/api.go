@@ -24,6 +24,11 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
+
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
 
 	"github.com/dexon-foundation/dexon/common"
 	"github.com/dexon-foundation/dexon/common/hexutil"
@@ -139,10 +144,141 @@ func (api *PrivateAdminAPI) IsProposing() bool {
 	return api.dex.IsProposing()
 }
 
+// RotateNodeKey generates a new node key and announces it to governance, the
+// current key is kept valid for 'graceRounds' rounds. Both keys are encrypted
+// with 'passphrase' into 'file', the node should be restarted with the new
+// key after the grace window.
+func (api *PrivateAdminAPI) RotateNodeKey(
+	file, passphrase string, graceRounds uint64) (string, error) {
+	rotation, err := api.dex.keyRotation.rotate(api.dex, graceRounds,
+		func(rotation *keystore.Rotation) error {
+			data, err := keystore.ExportRotation(rotation, passphrase,
+				keystore.StandardArgon2Time, keystore.StandardArgon2Memory)
+			if err != nil {
+				return err
+			}
+			// Never overwrite, it may be the key of a previous rotation.
+			out, err := os.OpenFile(
+				file, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+			_, err = out.Write(data)
+			return err
+		})
+	if err != nil {
+		return "", err
+	}
+	return coreTypes.NewNodeID(rotation.Current.PublicKey()).Hash.String(), nil
+}
+
+// VerifyHistory starts verifying finalized blocks from height 'from' to the
+// current block in background, the progress is reported by
+// HistoryVerification.
+func (api *PrivateAdminAPI) VerifyHistory(from uint64) (bool, error) {
+	if err := api.dex.history.start(api.dex, from); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HistoryVerification returns the progress of the last history verification.
+func (api *PrivateAdminAPI) HistoryVerification() *HistoryVerificationStatus {
+	return api.dex.history.status()
+}
+
 func (api *PrivateAdminAPI) NotaryInfo() (*NotaryInfo, error) {
 	return api.dex.protocolManager.NotaryInfo()
 }
 
+// PublicConsensusAPI provides estimations of consensus progress.
+type PublicConsensusAPI struct {
+	dex *Dexon
+}
+
+// NewPublicConsensusAPI creates a new API definition for the consensus
+// methods of the Dexon service.
+func NewPublicConsensusAPI(dex *Dexon) *PublicConsensusAPI {
+	return &PublicConsensusAPI{dex: dex}
+}
+
+// TimeEstimate is the estimated time a round or height begins.
+type TimeEstimate struct {
+	Round    uint64    `json:"round"`
+	Height   uint64    `json:"height"`
+	Time     time.Time `json:"time"`
+	Observed string    `json:"observedBlockInterval"`
+	Expected string    `json:"expectedBlockInterval"`
+}
+
+func newTimeEstimate(e dexCore.TimeEstimate) *TimeEstimate {
+	return &TimeEstimate{
+		Round:    e.Round,
+		Height:   e.Height,
+		Time:     e.Time,
+		Observed: e.Observed.String(),
+		Expected: e.Expected.String(),
+	}
+}
+
+// EstimateRoundTime estimates when 'round' begins, by configs of rounds ahead
+// and the block interval observed lately.
+func (api *PublicConsensusAPI) EstimateRoundTime(
+	round uint64) (*TimeEstimate, error) {
+	c := api.dex.bp.Consensus()
+	if c == nil {
+		return nil, errConsensusNotRunning
+	}
+	e, err := c.EstimateRoundTime(round)
+	if err != nil {
+		return nil, err
+	}
+	return newTimeEstimate(e), nil
+}
+
+// EstimateHeightTime estimates when the block at 'height' is produced.
+func (api *PublicConsensusAPI) EstimateHeightTime(
+	height uint64) (*TimeEstimate, error) {
+	c := api.dex.bp.Consensus()
+	if c == nil {
+		return nil, errConsensusNotRunning
+	}
+	e, err := c.EstimateHeightTime(height)
+	if err != nil {
+		return nil, err
+	}
+	return newTimeEstimate(e), nil
+}
+
+// GetFinalityProof returns the encoded finality proof of the block at
+// 'number', which could be verified offline by package proof of consensus.
+func (api *PublicConsensusAPI) GetFinalityProof(
+	number rpc.BlockNumber) (hexutil.Bytes, error) {
+	c := api.dex.bp.Consensus()
+	if c == nil {
+		return nil, errConsensusNotRunning
+	}
+	var block *types.Block
+	if number == rpc.LatestBlockNumber {
+		block = api.dex.blockchain.CurrentBlock()
+	} else {
+		block = api.dex.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	var b coreTypes.Block
+	if err := rlp.DecodeBytes(block.Header().DexconMeta, &b); err != nil {
+		return nil, err
+	}
+	p, err := c.FinalityProof(b.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return p.Encode()
+}
+
 // PublicDebugAPI is the collection of Ethereum full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
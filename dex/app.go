@@ -37,6 +37,10 @@ import (
 	"github.com/dexon-foundation/dexon/rlp"
 )
 
+// maxAsyncVerifications is the maximum number of blocks verified at once by
+// VerifyBlockAsync.
+const maxAsyncVerifications = 4
+
 // DexconApp implements the DEXON consensus core application interface.
 type DexconApp struct {
 	txPool     *core.TxPool
@@ -56,6 +60,8 @@ type DexconApp struct {
 	addressCounter  map[common.Address]uint64
 	undeliveredNum  uint64
 	deliveredHeight uint64
+
+	verifySlots chan struct{}
 }
 
 func NewDexconApp(txPool *core.TxPool, blockchain *core.BlockChain, gov *DexconGovernance,
@@ -71,7 +77,38 @@ func NewDexconApp(txPool *core.TxPool, blockchain *core.BlockChain, gov *DexconG
 		addressCost:     map[common.Address]*big.Int{},
 		addressCounter:  map[common.Address]uint64{},
 		deliveredHeight: blockchain.CurrentBlock().NumberU64(),
+		verifySlots:     make(chan struct{}, maxAsyncVerifications),
+	}
+}
+
+// feeSummary summarizes fees of 'txs': the total is the sum of the maximum
+// fee, i.e. gas price times gas limit, of each transaction, and the minimum is
+// the lowest gas price.
+func feeSummary(txs types.Transactions) *coreTypes.FeeSummary {
+	fee := &coreTypes.FeeSummary{
+		Count: uint64(len(txs)),
+		Total: new(big.Int),
+		Min:   new(big.Int),
+	}
+	for i, tx := range txs {
+		fee.Total.Add(fee.Total, new(big.Int).Mul(
+			tx.GasPrice(), new(big.Int).SetUint64(tx.Gas())))
+		if i == 0 || tx.GasPrice().Cmp(fee.Min) < 0 {
+			fee.Min.Set(tx.GasPrice())
+		}
+	}
+	return fee
+}
+
+// sameFeeSummary returns whether 'a' and 'b' summarize the same fees.
+func sameFeeSummary(a, b *coreTypes.FeeSummary) bool {
+	cmp := func(x, y *big.Int) bool {
+		if x == nil || y == nil {
+			return x == nil && y == nil
+		}
+		return x.Cmp(y) == 0
 	}
+	return a.Count == b.Count && cmp(a.Total, b.Total) && cmp(a.Min, b.Min)
 }
 
 // validateNonce check if nonce is in order and return first nonce of every address.
@@ -151,6 +188,24 @@ func (d *DexconApp) PreparePayload(position coreTypes.Position) (payload []byte,
 	return
 }
 
+// PreparePayloadWithFee prepares the payload like PreparePayload, along with
+// the fee summary of its transactions.
+func (d *DexconApp) PreparePayloadWithFee(position coreTypes.Position) (
+	payload []byte, fee *coreTypes.FeeSummary, err error) {
+	payload, err = d.PreparePayload(position)
+	if err != nil {
+		return
+	}
+	var txs types.Transactions
+	if len(payload) > 0 {
+		if err = rlp.DecodeBytes(payload, &txs); err != nil {
+			return
+		}
+	}
+	fee = feeSummary(txs)
+	return
+}
+
 func (d *DexconApp) preparePayload(ctx context.Context, position coreTypes.Position) (
 	payload []byte, err error) {
 	d.appMu.RLock()
@@ -315,6 +370,11 @@ func (d *DexconApp) VerifyBlock(block *coreTypes.Block) coreTypes.BlockVerifySta
 
 	var transactions types.Transactions
 	if len(block.Payload) == 0 {
+		if block.FeeSummary != nil &&
+			!sameFeeSummary(block.FeeSummary, feeSummary(nil)) {
+			log.Error("Fee summary mismatch", "fee", block.FeeSummary)
+			return coreTypes.VerifyInvalidBlock
+		}
 		return coreTypes.VerifyOK
 	}
 
@@ -330,6 +390,12 @@ func (d *DexconApp) VerifyBlock(block *coreTypes.Block) coreTypes.BlockVerifySta
 		return coreTypes.VerifyInvalidBlock
 	}
 
+	if block.FeeSummary != nil &&
+		!sameFeeSummary(block.FeeSummary, feeSummary(transactions)) {
+		log.Error("Fee summary mismatch", "fee", block.FeeSummary)
+		return coreTypes.VerifyInvalidBlock
+	}
+
 	_, err = types.GlobalSigCache.Add(types.NewEIP155Signer(d.blockchain.Config().ChainID), transactions)
 	if err != nil {
 		log.Error("Failed to calculate sender", "error", err)
@@ -410,6 +476,40 @@ func (d *DexconApp) VerifyBlock(block *coreTypes.Block) coreTypes.BlockVerifySta
 	return coreTypes.VerifyOK
 }
 
+// VerifyBlockAsync verifies the block in another goroutine, consensus keeps
+// its BA steps on schedule instead of waiting for it. At most
+// maxAsyncVerifications blocks are verified at once, others are retried later
+// by consensus. Results of positions already confirmed are dropped.
+func (d *DexconApp) VerifyBlockAsync(block *coreTypes.Block,
+	done func(coreTypes.BlockVerifyStatus)) {
+	select {
+	case d.verifySlots <- struct{}{}:
+	default:
+		done(coreTypes.VerifyRetryLater)
+		return
+	}
+	go func() {
+		status := coreTypes.VerifyRetryLater
+		if !d.isConfirmed(block.Position) {
+			status = d.VerifyBlock(block)
+			if d.isConfirmed(block.Position) {
+				log.Debug("Drop verification result of confirmed position",
+					"position", block.Position.String(), "status", status)
+				status = coreTypes.VerifyRetryLater
+			}
+		}
+		<-d.verifySlots
+		done(status)
+	}()
+}
+
+// isConfirmed checks if a block is confirmed at the position already.
+func (d *DexconApp) isConfirmed(position coreTypes.Position) bool {
+	d.appMu.RLock()
+	defer d.appMu.RUnlock()
+	return position.Height <= d.deliveredHeight+d.undeliveredNum
+}
+
 // BlockDelivered is called when a block is add to the compaction chain.
 func (d *DexconApp) BlockDelivered(
 	blockHash coreCommon.Hash,
@@ -2292,6 +2292,78 @@ func TestDexonApp(t *testing.T) {
 	}
 }
 
+func TestFeeSummary(t *testing.T) {
+	fee := feeSummary(nil)
+	if fee.Count != 0 || fee.Total.Sign() != 0 || fee.Min.Sign() != 0 {
+		t.Fatalf("unexpected empty fee summary: %v", fee)
+	}
+
+	txs := types.Transactions{
+		types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000,
+			big.NewInt(3), nil),
+		types.NewTransaction(1, common.Address{}, big.NewInt(0), 50000,
+			big.NewInt(2), nil),
+	}
+	fee = feeSummary(txs)
+	expect := &coreTypes.FeeSummary{
+		Count: 2,
+		Total: big.NewInt(3*21000 + 2*50000),
+		Min:   big.NewInt(2),
+	}
+	if !sameFeeSummary(fee, expect) {
+		t.Fatalf("fee summary mismatch: %v != %v", fee, expect)
+	}
+	expect.Min = big.NewInt(3)
+	if sameFeeSummary(fee, expect) {
+		t.Fatalf("different fee summaries should mismatch")
+	}
+	if sameFeeSummary(fee, &coreTypes.FeeSummary{Count: 2}) {
+		t.Fatalf("fee summary with nil amounts should mismatch")
+	}
+}
+
+func TestVerifyBlockAsync(t *testing.T) {
+	app := &DexconApp{
+		deliveredHeight: 10,
+		undeliveredNum:  2,
+		verifySlots:     make(chan struct{}, maxAsyncVerifications),
+	}
+	verify := func(height uint64) coreTypes.BlockVerifyStatus {
+		ch := make(chan coreTypes.BlockVerifyStatus, 1)
+		app.VerifyBlockAsync(&coreTypes.Block{
+			Position: coreTypes.Position{Height: height},
+		}, func(status coreTypes.BlockVerifyStatus) {
+			ch <- status
+		})
+		select {
+		case status := <-ch:
+			return status
+		case <-time.After(time.Second):
+			t.Fatalf("height %d: not verified within 1 second", height)
+		}
+		return coreTypes.VerifyRetryLater
+	}
+
+	// Positions already confirmed are not verified.
+	for _, height := range []uint64{10, 12} {
+		if status := verify(height); status != coreTypes.VerifyRetryLater {
+			t.Errorf("height %d: got status %v, want %v",
+				height, status, coreTypes.VerifyRetryLater)
+		}
+	}
+	// The block is verified, and rejected for the witness.
+	if status := verify(13); status != coreTypes.VerifyInvalidBlock {
+		t.Errorf("got status %v, want %v", status, coreTypes.VerifyInvalidBlock)
+	}
+	// Verifications beyond the limit are retried later.
+	for i := 0; i < maxAsyncVerifications; i++ {
+		app.verifySlots <- struct{}{}
+	}
+	if status := verify(13); status != coreTypes.VerifyRetryLater {
+		t.Errorf("got status %v, want %v", status, coreTypes.VerifyRetryLater)
+	}
+}
+
 func newDexon(masterKey *ecdsa.PrivateKey, accountNum int) (*Dexon, []*ecdsa.PrivateKey, error) {
 	db := ethdb.NewMemDatabase()
 
@@ -2343,6 +2415,7 @@ func newDexon(masterKey *ecdsa.PrivateKey, accountNum int) (*Dexon, []*ecdsa.Pri
 	}
 
 	txPoolConfig := core.DefaultTxPoolConfig
+	txPoolConfig.Journal = ""
 	dex.txPool = core.NewTxPool(txPoolConfig, chainConfig, dex.blockchain)
 
 	dex.APIBackend = &DexAPIBackend{dex, nil}
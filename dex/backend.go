@@ -73,7 +73,9 @@ type Dexon struct {
 	governance *DexconGovernance
 	network    *DexconNetwork
 
-	bp *blockProposer
+	bp          *blockProposer
+	keyRotation nodeKeyRotation
+	history     historyVerification
 
 	networkID     uint64
 	netRPCService *ethapi.PublicNetAPI
@@ -82,6 +84,10 @@ type Dexon struct {
 }
 
 func New(ctx *node.ServiceContext, config *Config) (*Dexon, error) {
+	if !config.BlockPropagation.IsValid() {
+		return nil, fmt.Errorf("invalid block propagation %q",
+			config.BlockPropagation)
+	}
 	// Consensus.
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
@@ -178,6 +184,13 @@ func New(ctx *node.ServiceContext, config *Config) (*Dexon, error) {
 		return nil, err
 	}
 
+	if config.LightServ > 0 {
+		pm.capabilities |= capLightServer
+	}
+	pm.blockPropagation = config.BlockPropagation
+	pm.cache.setRetention(config.VoteRetention)
+	pm.notaryPeerLimit = config.NotaryPeerLimit
+	pm.notaryPeerMargin = config.NotaryPeerMargin
 	dex.protocolManager = pm
 	dex.network = NewDexconNetwork(pm)
 
@@ -212,6 +225,11 @@ func (s *Dexon) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   filters.NewPublicFilterAPI(s.APIBackend, false),
 			Public:    true,
+		}, {
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   NewPublicConsensusAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "admin",
 			Version:   "1.0",
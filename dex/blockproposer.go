@@ -15,11 +15,14 @@ import (
 	"github.com/dexon-foundation/dexon/core"
 	"github.com/dexon-foundation/dexon/dex/db"
 	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/params"
 	"github.com/dexon-foundation/dexon/rlp"
 )
 
 var (
 	forceSyncTimeout = 20 * time.Second
+
+	errConsensusNotRunning = errors.New("consensus core is not running")
 )
 
 type blockProposer struct {
@@ -33,6 +36,9 @@ type blockProposer struct {
 
 	wg     sync.WaitGroup
 	stopCh chan struct{}
+
+	// consensus is the running consensus core, if any.
+	consensus atomic.Value
 }
 
 func NewBlockProposer(dex *Dexon, watchCat *syncer.WatchCat, dMoment time.Time) *blockProposer {
@@ -64,7 +70,7 @@ func (b *blockProposer) Start() error {
 			// Start receiving core messages.
 			b.dex.protocolManager.SetReceiveCoreMessage(true)
 
-			c = b.initConsensus()
+			c, err = b.initConsensus()
 		} else {
 			c, err = b.syncConsensus()
 		}
@@ -82,10 +88,32 @@ func (b *blockProposer) Start() error {
 
 func (b *blockProposer) run(c *dexCore.Consensus) {
 	log.Info("Start running consensus core")
-	go c.Run()
+	c.SetHeartbeatVersion(params.VersionWithMeta)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- c.Run()
+	}()
+	b.consensus.Store(c)
 	atomic.StoreInt32(&b.proposing, 1)
-	<-b.stopCh
-	log.Debug("Block proposer receive stop signal")
+	ticker := time.NewTicker(nodeHeightMetricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stopCh:
+			log.Debug("Block proposer receive stop signal")
+			return
+		case err := <-runErr:
+			if err != nil {
+				log.Error("Consensus core failed to run", "err", err)
+				c.Stop()
+			}
+			atomic.StoreInt32(&b.proposing, 0)
+			return
+		case <-ticker.C:
+			updateNodeHeightMetrics(c.NodeHeights())
+			b.dex.keyRotation.check(b.dex.governance.Round())
+		}
+	}
 }
 
 func (b *blockProposer) Stop() {
@@ -102,6 +130,13 @@ func (b *blockProposer) Stop() {
 	log.Info("Block proposer stopped")
 }
 
+// Consensus returns the running consensus core, or nil if it's not running
+// yet.
+func (b *blockProposer) Consensus() *dexCore.Consensus {
+	c, _ := b.consensus.Load().(*dexCore.Consensus)
+	return c
+}
+
 func (b *blockProposer) IsCoreSyncing() bool {
 	return atomic.LoadInt32(&b.syncing) == 1
 }
@@ -110,11 +145,19 @@ func (b *blockProposer) IsProposing() bool {
 	return atomic.LoadInt32(&b.proposing) == 1
 }
 
-func (b *blockProposer) initConsensus() *dexCore.Consensus {
+func (b *blockProposer) initConsensus() (*dexCore.Consensus, error) {
+	genesis, err := dexCore.ValidateGenesis(b.dex.governance)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Validated genesis governance state",
+		"crs", genesis.CRS, "nodes", len(genesis.NodeSet),
+		"notaries", len(genesis.NotarySet))
 	db := db.NewDatabase(b.dex.chainDb)
 	privkey := coreEcdsa.NewPrivateKeyFromECDSA(b.dex.config.PrivateKey)
 	return dexCore.NewConsensus(b.dMoment,
-		b.dex.app, b.dex.governance, db, b.dex.network, privkey, log.Root())
+		b.dex.app, b.dex.governance, db, b.dex.network, privkey,
+		log.Root())
 }
 
 func (b *blockProposer) syncConsensus() (*dexCore.Consensus, error) {
@@ -226,7 +269,7 @@ ListenLoop:
 			return nil, errors.New("early stop")
 		case <-time.After(forceSyncTimeout):
 			log.Debug("no new chain head for a while")
-			if p := b.dex.protocolManager.peers.BestPeer(); p != nil {
+			if p := b.dex.protocolManager.peers.BestPeerWithCapabilities(capArchive); p != nil {
 				log.Debug("try force sync with peer", "id", p.id)
 				go b.dex.protocolManager.synchronise(p, true)
 			} else {
@@ -52,6 +52,8 @@ type cache struct {
 	db                  coreDb.Database
 	voteSize            int
 	size                int
+	finalizedHeight     uint64
+	retention           uint64
 }
 
 func newCache(size int, db coreDb.Database) *cache {
@@ -64,9 +66,59 @@ func newCache(size int, db coreDb.Database) *cache {
 	}
 }
 
+// setRetention sets the count of heights behind the latest finalized block to
+// keep votes and finalized blocks, zero means no pruning by finalization.
+func (c *cache) setRetention(retention uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.retention = retention
+	c.pruneNoLock()
+}
+
+// prunedBelow returns the height below which votes and finalized blocks are
+// pruned.
+func (c *cache) prunedBelow() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.prunedBelowNoLock()
+}
+
+func (c *cache) prunedBelowNoLock() uint64 {
+	if c.retention == 0 || c.finalizedHeight < c.retention {
+		return 0
+	}
+	return c.finalizedHeight - c.retention + 1
+}
+
+func (c *cache) pruneNoLock() {
+	below := c.prunedBelowNoLock()
+	if below == 0 {
+		return
+	}
+	positions := c.votePosition[:0]
+	for _, pos := range c.votePosition {
+		if pos.Height >= below {
+			positions = append(positions, pos)
+			continue
+		}
+		c.voteSize -= len(c.voteCache[pos])
+		delete(c.voteCache, pos)
+	}
+	c.votePosition = positions
+	for pos, b := range c.finalizedBlockCache {
+		if pos.Height < below {
+			delete(c.blockCache, b.Hash)
+			delete(c.finalizedBlockCache, pos)
+		}
+	}
+}
+
 func (c *cache) addVote(vote *coreTypes.Vote) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if vote.Position.Height < c.prunedBelowNoLock() {
+		return
+	}
 	if c.voteSize >= c.size {
 		pos := c.votePosition[0]
 		c.voteSize -= len(c.voteCache[pos])
@@ -137,6 +189,9 @@ func (c *cache) addFinalizedBlock(block *coreTypes.Block) {
 }
 
 func (c *cache) addFinalizedBlockNoLock(block *coreTypes.Block) {
+	if block.Position.Height < c.prunedBelowNoLock() {
+		return
+	}
 	block = block.Clone()
 	if len(c.blockCache) >= c.size {
 		// Randomly delete one entry.
@@ -154,6 +209,10 @@ func (c *cache) addFinalizedBlockNoLock(block *coreTypes.Block) {
 	}
 	c.blockCache[block.Hash] = block
 	c.finalizedBlockCache[block.Position] = block
+	if block.Position.Height > c.finalizedHeight {
+		c.finalizedHeight = block.Position.Height
+		c.pruneNoLock()
+	}
 }
 
 func (c *cache) blocks(hashes coreCommon.Hashes, includeDB bool) []*coreTypes.Block {
@@ -324,3 +324,64 @@ func randomBytes() []byte {
 	}
 	return bytes
 }
+
+func TestCachePruneByFinalization(t *testing.T) {
+	db, err := coreDb.NewMemBackedDB()
+	if err != nil {
+		panic(err)
+	}
+	cache := newCache(100, db)
+	cache.setRetention(3)
+	newVote := func(height uint64) *coreTypes.Vote {
+		return &coreTypes.Vote{
+			VoteHeader: coreTypes.VoteHeader{
+				BlockHash: coreCommon.NewRandomHash(),
+				Position:  coreTypes.Position{Height: height},
+			},
+		}
+	}
+	newBlock := func(height uint64) *coreTypes.Block {
+		return &coreTypes.Block{
+			Hash:       coreCommon.NewRandomHash(),
+			Position:   coreTypes.Position{Height: height},
+			Randomness: []byte{0x1},
+		}
+	}
+	for height := uint64(1); height <= 5; height++ {
+		cache.addVote(newVote(height))
+	}
+	if below := cache.prunedBelow(); below != 0 {
+		t.Errorf("pruned below %d before any finalized block", below)
+	}
+	blocks := make(map[uint64]*coreTypes.Block)
+	for height := uint64(1); height <= 5; height++ {
+		blocks[height] = newBlock(height)
+		cache.addFinalizedBlock(blocks[height])
+	}
+	// Heights 3, 4, 5 are kept.
+	if below := cache.prunedBelow(); below != 3 {
+		t.Errorf("expect pruned below 3, got %d", below)
+	}
+	for height := uint64(1); height <= 5; height++ {
+		pos := coreTypes.Position{Height: height}
+		pruned := height < 3
+		if votes := cache.votes(pos); (len(votes) == 0) != pruned {
+			t.Errorf("height %d: unexpected votes %v", height, votes)
+		}
+		if b := cache.finalizedBlock(pos); (b == nil) != pruned {
+			t.Errorf("height %d: unexpected finalized block %v", height, b)
+		}
+		hashes := coreCommon.Hashes{blocks[height].Hash}
+		if bs := cache.blocks(hashes, false); (len(bs) == 0) != pruned {
+			t.Errorf("height %d: unexpected blocks %v", height, bs)
+		}
+	}
+	// Votes of pruned heights are not cached again.
+	cache.addVote(newVote(2))
+	if votes := cache.votes(coreTypes.Position{Height: 2}); len(votes) != 0 {
+		t.Errorf("votes of pruned height are cached: %v", votes)
+	}
+	if cache.voteSize != 3 {
+		t.Errorf("expect vote size 3, got %d", cache.voteSize)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+
+	"github.com/dexon-foundation/dexon/p2p"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+var errNonCanonical = errors.New("non-canonical encoding")
+
+// strictEncoding returns if consensus messages must be in canonical encoding.
+func (pm *ProtocolManager) strictEncoding() bool {
+	return pm.chainconfig.IsCanonicalEncoding(
+		pm.blockchain.CurrentBlock().Number())
+}
+
+// decodeCoreMsg decodes a consensus message. Once canonical encoding is
+// activated, the message is rejected unless encoding the decoded value gives
+// the exact payload, so trailing data, unused list elements and other
+// alternative encodings never reach the consensus core, whose hashes might
+// differ across implementations.
+func (pm *ProtocolManager) decodeCoreMsg(msg p2p.Msg, val interface{}) error {
+	if !pm.strictEncoding() {
+		return msg.Decode(val)
+	}
+	return decodeCanonical(msg, val)
+}
+
+func decodeCanonical(msg p2p.Msg, val interface{}) error {
+	payload, err := ioutil.ReadAll(msg.Payload)
+	if err != nil {
+		return err
+	}
+	if err := rlp.DecodeBytes(payload, val); err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(payload, enc) {
+		return errNonCanonical
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+
+	"github.com/dexon-foundation/dexon/p2p"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+func newCanonicalTestMsg(code uint64, payload []byte) p2p.Msg {
+	return p2p.Msg{
+		Code:    code,
+		Size:    uint32(len(payload)),
+		Payload: bytes.NewReader(payload),
+	}
+}
+
+func TestDecodeCanonical(t *testing.T) {
+	block := &coreTypes.Block{
+		ProposerID: coreTypes.NodeID{Hash: coreCommon.NewRandomHash()},
+		ParentHash: coreCommon.NewRandomHash(),
+		Hash:       coreCommon.NewRandomHash(),
+		Position:   coreTypes.Position{Round: 1, Height: 10},
+		Timestamp:  time.Now().UTC(),
+		Payload:    []byte{1, 2, 3},
+	}
+	vote := coreTypes.NewVote(coreTypes.VoteCom, coreCommon.NewRandomHash(), 3)
+	vote.Position = coreTypes.Position{Round: 1, Height: 10}
+
+	for _, val := range []interface{}{[]*coreTypes.Block{block}, []*coreTypes.Vote{vote}} {
+		payload, err := rlp.EncodeToBytes(val)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+
+		var err1 error
+		switch val.(type) {
+		case []*coreTypes.Block:
+			var blocks []*coreTypes.Block
+			err1 = decodeCanonical(newCanonicalTestMsg(CoreBlockMsg, payload), &blocks)
+			if err1 == nil && blocks[0].Hash != block.Hash {
+				t.Errorf("block mismatch")
+			}
+		case []*coreTypes.Vote:
+			var votes []*coreTypes.Vote
+			err1 = decodeCanonical(newCanonicalTestMsg(VoteMsg, payload), &votes)
+			if err1 == nil && votes[0].BlockHash != vote.BlockHash {
+				t.Errorf("vote mismatch")
+			}
+		}
+		if err1 != nil {
+			t.Errorf("canonical payload rejected: %v", err1)
+		}
+	}
+
+	// Trailing data after the vote list is ignored by a plain decode.
+	payload, err := rlp.EncodeToBytes([]*coreTypes.Vote{vote})
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	payload = append(payload, 0x80)
+	var votes []*coreTypes.Vote
+	if err := newCanonicalTestMsg(VoteMsg, payload).Decode(&votes); err != nil {
+		t.Fatalf("plain decode failed: %v", err)
+	}
+	if err := decodeCanonical(
+		newCanonicalTestMsg(VoteMsg, payload), &votes); err == nil {
+		t.Errorf("trailing data accepted")
+	}
+}
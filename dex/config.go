@@ -50,6 +50,7 @@ var DefaultConfig = Config{
 	},
 	BlockProposerEnabled: false,
 	DefaultGasPrice:      big.NewInt(params.GWei),
+	VoteRetention:        1024,
 	Indexer:              indexer.Config{},
 }
 
@@ -67,6 +68,30 @@ func init() {
 	}
 }
 
+// BlockPropagation is the strategy to propagate core blocks to notary set
+// peers.
+type BlockPropagation string
+
+const (
+	// PushPropagation sends full blocks to all notary set peers.
+	PushPropagation BlockPropagation = "push"
+	// PushPullPropagation sends full blocks to a subset of notary set peers
+	// and announces them to the rest, which pull bodies on demand. It cuts
+	// the bandwidth of proposers for large payloads. Announcements are only
+	// sent to dex65 peers, peers of older versions still receive full
+	// blocks.
+	PushPullPropagation BlockPropagation = "push-pull"
+)
+
+// IsValid checks if the strategy is known, an empty one means push.
+func (p BlockPropagation) IsValid() bool {
+	switch p {
+	case "", PushPropagation, PushPullPropagation:
+		return true
+	}
+	return false
+}
+
 //go:generate gencodec -type Config -formats toml -out gen_config.go
 
 type Config struct {
@@ -128,6 +153,20 @@ type Config struct {
 	// Dexon options
 	DMoment int64
 
+	// BlockPropagation is the strategy to propagate core blocks, push by
+	// default.
+	BlockPropagation BlockPropagation
+
+	// VoteRetention is the count of heights behind the latest finalized
+	// block to keep votes for peers to pull, zero to keep them until evicted
+	// by the cache size.
+	VoteRetention uint64
+
+	// NotaryPeerLimit derives the peer limit from sizes of the current and
+	// next notary sets plus NotaryPeerMargin, instead of the static limit.
+	NotaryPeerLimit  bool
+	NotaryPeerMargin int
+
 	// Indexer config
 	Indexer indexer.Config
 
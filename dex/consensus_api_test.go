@@ -0,0 +1,189 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	"github.com/dexon-foundation/dexon-consensus/core/consensusapi"
+	"github.com/dexon-foundation/dexon-consensus/core/devnet"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	"github.com/dexon-foundation/dexon-consensus/core/watcher"
+)
+
+func newTestDevNode(t *testing.T) *devnet.Node {
+	node, err := devnet.New(devnet.Config{
+		App:         test.NewScriptedApp(),
+		RoundLength: 50,
+	})
+	if err != nil {
+		t.Fatalf("new dev node error: %v", err)
+	}
+	return node
+}
+
+func TestConsensusAPINode(t *testing.T) {
+	if _, err := consensusapi.New(
+		consensusapi.Config{}); err != consensusapi.ErrMissingConfig {
+		t.Fatalf("unexpected error of missing config: %v", err)
+	}
+	if _, err := devnet.New(devnet.Config{}); err != devnet.ErrMissingApp {
+		t.Fatalf("unexpected error of missing app: %v", err)
+	}
+
+	node := newTestDevNode(t)
+	if err := node.Node.Stop(); err != consensusapi.ErrNotStarted {
+		t.Fatalf("unexpected error of stopping before started: %v", err)
+	}
+	finalized := make(chan consensusapi.FinalizedBlock, 16)
+	sub := node.SubscribeFinalized(finalized)
+	if err := node.Start(); err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if err := node.Start(); err != consensusapi.ErrStarted {
+		t.Fatalf("unexpected error of starting twice: %v", err)
+	}
+	if err := node.Feed("vote"); err != consensusapi.ErrUnsupportedMessage {
+		t.Fatalf("unexpected error of unsupported message: %v", err)
+	}
+
+	// Blocks are finalized in order across rounds.
+	var last consensusapi.FinalizedBlock
+	for last.Height < 80 {
+		select {
+		case b := <-finalized:
+			if last.Height != 0 && b.Height != last.Height+1 {
+				t.Fatalf("finalized height skipped: %d -> %d",
+					last.Height, b.Height)
+			}
+			last = b
+		case <-time.After(10 * time.Second):
+			t.Fatalf("finalization timeout at height %d", last.Height)
+		}
+	}
+	sub.Unsubscribe()
+	if last.Round == 0 {
+		t.Fatalf("round not advanced at height %d", last.Height)
+	}
+	if len(last.Randomness) == 0 {
+		t.Fatalf("no randomness of block in round %d", last.Round)
+	}
+	if s := node.Status(); s.Height <= last.Height-1 || s.Confirmed == 0 {
+		t.Fatalf("unexpected status: %+v", s)
+	}
+
+	if err := node.Stop(); err != nil {
+		t.Fatalf("stop error: %v", err)
+	}
+	if err := node.Node.Stop(); err != consensusapi.ErrNotStarted {
+		t.Fatalf("unexpected error of stopping twice: %v", err)
+	}
+}
+
+// testWatcherGovernance overrides CRS of the wrapped governance once forged.
+type testWatcherGovernance struct {
+	*test.Governance
+
+	lock   sync.Mutex
+	forged coreCommon.Hash
+}
+
+func (g *testWatcherGovernance) CRS(round uint64) coreCommon.Hash {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if (g.forged != coreCommon.Hash{}) {
+		return g.forged
+	}
+	return g.Governance.CRS(round)
+}
+
+func (g *testWatcherGovernance) forge() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.forged = coreCommon.NewRandomHash()
+}
+
+func TestWatcherAlerts(t *testing.T) {
+	if _, err := watcher.New(watcher.Config{}); err != watcher.ErrMissingNode {
+		t.Fatalf("unexpected error of missing node: %v", err)
+	}
+	node := newTestDevNode(t)
+	if _, err := watcher.New(watcher.Config{
+		Node: node.Node}); err != watcher.ErrMissingGovernance {
+		t.Fatalf("unexpected error of missing governance: %v", err)
+	}
+
+	gov := &testWatcherGovernance{Governance: node.Governance}
+	alerts := make(chan *watcher.Alert, 16)
+	w, err := watcher.New(watcher.Config{
+		Node:         node.Node,
+		Gov:          gov,
+		StallTimeout: 300 * time.Millisecond,
+		PollInterval: 50 * time.Millisecond,
+		Handlers: []watcher.AlertHandler{func(a *watcher.Alert) {
+			select {
+			case alerts <- a:
+			default:
+			}
+		}},
+	})
+	if err != nil {
+		t.Fatalf("new watcher error: %v", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start node error: %v", err)
+	}
+	if err := w.Start(); err != nil {
+		t.Fatalf("start watcher error: %v", err)
+	}
+	defer w.Stop()
+	if err := w.Start(); err != watcher.ErrStarted {
+		t.Fatalf("unexpected error of starting twice: %v", err)
+	}
+
+	// Alerts of other types are skipped, ex. changes of the next round raised
+	// along with the current one.
+	waitAlert := func(alertType watcher.AlertType) *watcher.Alert {
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case a := <-alerts:
+				if a.Type == alertType {
+					return a
+				}
+			case <-timeout:
+				t.Fatalf("no %s alert raised", alertType)
+			}
+		}
+	}
+	// Governance is observed in the first polls before forged.
+	time.Sleep(200 * time.Millisecond)
+	gov.forge()
+	if a := waitAlert(watcher.AlertGovernanceChange); a.Height == 0 {
+		t.Fatalf("alert raised before any block finalized: %v", a)
+	}
+
+	// A stopped node finalizes nothing.
+	if err := node.Stop(); err != nil {
+		t.Fatalf("stop node error: %v", err)
+	}
+	waitAlert(watcher.AlertStall)
+}
@@ -115,4 +115,86 @@ func (d *DB) GetDKGProtocol() (
 	return *dkgProtocol, nil
 }
 
+func (d *DB) PutPendingBA(info coreDb.PendingBAInfo) error {
+	return rawdb.WriteCorePendingBA(d.db, &info)
+}
+
+func (d *DB) GetPendingBA() (coreDb.PendingBAInfo, error) {
+	info := rawdb.ReadCorePendingBA(d.db)
+	if info == nil {
+		return coreDb.PendingBAInfo{}, coreDb.ErrPendingBADoesNotExist
+	}
+	return *info, nil
+}
+
+func (d *DB) PutAgreementCheckpoint(s coreTypes.AgreementSnapshot) error {
+	return rawdb.WriteCoreAgreementCheckpoint(d.db, &s)
+}
+
+func (d *DB) GetAgreementCheckpoint() (coreTypes.AgreementSnapshot, error) {
+	s := rawdb.ReadCoreAgreementCheckpoint(d.db)
+	if s == nil {
+		return coreTypes.AgreementSnapshot{},
+			coreDb.ErrAgreementCheckpointDoesNotExist
+	}
+	return *s, nil
+}
+
+func (d *DB) PutSignWatermarks(marks []coreTypes.SignWatermark) error {
+	return rawdb.WriteCoreSignWatermarks(d.db, marks)
+}
+
+func (d *DB) GetSignWatermarks() ([]coreTypes.SignWatermark, error) {
+	marks, err := rawdb.ReadCoreSignWatermarks(d.db)
+	if err != nil {
+		return nil, err
+	}
+	if marks == nil {
+		return nil, coreDb.ErrSignWatermarksDoNotExist
+	}
+	return marks, nil
+}
+
+func (d *DB) PutVoteWatermark(pos coreTypes.Position) error {
+	return rawdb.WriteCoreVoteWatermark(d.db, pos)
+}
+
+func (d *DB) GetVoteWatermark() (coreTypes.Position, error) {
+	pos, err := rawdb.ReadCoreVoteWatermark(d.db)
+	if err != nil {
+		return coreTypes.Position{}, err
+	}
+	if pos == nil {
+		return coreTypes.Position{}, coreDb.ErrVoteWatermarkDoesNotExist
+	}
+	return *pos, nil
+}
+
+func (d *DB) PutRoundBlocks(round uint64, hashes coreCommon.Hashes) error {
+	return rawdb.WriteCoreRoundBlocks(d.db, round, hashes)
+}
+
+func (d *DB) GetRoundBlocks(round uint64) (coreCommon.Hashes, error) {
+	hashes, err := rawdb.ReadCoreRoundBlocks(d.db, round)
+	if err != nil {
+		return nil, err
+	}
+	if hashes == nil {
+		return nil, coreDb.ErrRoundBlocksDoNotExist
+	}
+	return hashes, nil
+}
+
+func (d *DB) PutSchemaVersion(version uint64) error {
+	return rawdb.WriteCoreSchemaVersion(d.db, version)
+}
+
+func (d *DB) GetSchemaVersion() (uint64, error) {
+	version := rawdb.ReadCoreSchemaVersion(d.db)
+	if version == nil {
+		return 0, coreDb.ErrSchemaVersionDoesNotExist
+	}
+	return *version, nil
+}
+
 func (d *DB) Close() error { return nil }
@@ -0,0 +1,249 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
+	"github.com/dexon-foundation/dexon-consensus/core/light"
+	"github.com/dexon-foundation/dexon-consensus/core/proof"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// newTestCoreCluster runs a single node cluster of short rounds until the
+// block at 'height' is delivered. The cluster should be stopped by the
+// caller.
+func newTestCoreCluster(t *testing.T, height uint64) (
+	*test.Cluster, *test.ClusterNode) {
+	config := test.NewConfigBuilder(1).
+		LambdaBA(5 * time.Millisecond).
+		LambdaDKG(10 * time.Millisecond).
+		MinBlockInterval(10 * time.Millisecond).
+		RoundLength(50).
+		Build()
+	c, err := test.NewClusterBuilder(1).
+		Config(config).
+		DMoment(time.Now().UTC()).
+		Logger(func(coreTypes.NodeID) coreCommon.Logger {
+			return &coreCommon.NullLogger{}
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("build cluster error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("start cluster error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := c.WaitForHeight(ctx, height); err != nil {
+		c.Stop()
+		t.Fatalf("wait for height %d error: %v", height, err)
+	}
+	return c, c.Node(c.NodeIDs()[0])
+}
+
+// deliveredTestBlock returns the first block delivered by 'node' in 'round'
+// which is not empty.
+func deliveredTestBlock(t *testing.T, node *test.ClusterNode,
+	round uint64) *coreTypes.Block {
+	for _, d := range node.Delivered() {
+		if d.Position.Round != round {
+			continue
+		}
+		b, err := node.DB.GetBlock(d.Hash)
+		if err != nil {
+			t.Fatalf("get block error: %v", err)
+		}
+		if !b.IsEmpty() {
+			return &b
+		}
+	}
+	t.Fatalf("no block delivered in round %d", round)
+	return nil
+}
+
+func TestExportGroupPublicKeys(t *testing.T) {
+	c, node := newTestCoreCluster(t, 200)
+	defer c.Stop()
+
+	// Rounds before DKGDelayRound have no DKG.
+	records, err := node.Consensus.ExportGroupPublicKeys(0, 2)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if len(records) != 2 || records[0].Round != 1 || records[1].Round != 2 {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	if _, exist := records[0].NotarySet()[node.ID]; !exist {
+		t.Fatalf("node not in notary set of record")
+	}
+	gpks, err := dexCore.ImportGroupPublicKeys(records)
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if len(gpks) != 2 {
+		t.Fatalf("unexpected group public keys: %d", len(gpks))
+	}
+
+	if _, err := dexCore.ImportGroupPublicKeys(
+		[]*dexCore.GroupPublicKeyRecord{records[1], records[0]}); err !=
+		dexCore.ErrGPKRecordNotOrdered {
+		t.Fatalf("unexpected error of unordered records: %v", err)
+	}
+	// The CRS of a later round must be certified by its previous one.
+	forged := *records[1]
+	forged.CRS = coreCommon.NewRandomHash()
+	if _, err := dexCore.ImportGroupPublicKeys(
+		[]*dexCore.GroupPublicKeyRecord{records[0], &forged}); err == nil {
+		t.Fatalf("record of forged CRS is imported")
+	}
+	if _, err := node.Consensus.ExportGroupPublicKeys(1, 100); err == nil {
+		t.Fatalf("round whose DKG is not final is exported")
+	}
+}
+
+func TestFinalityProof(t *testing.T) {
+	c, node := newTestCoreCluster(t, 200)
+	defer c.Stop()
+
+	records, err := node.Consensus.ExportGroupPublicKeys(1, 1)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	gpks, err := dexCore.ImportGroupPublicKeys(records)
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	b := deliveredTestBlock(t, node, 1)
+	p, err := node.Consensus.FinalityProof(b.Hash)
+	if err != nil {
+		t.Fatalf("finality proof error: %v", err)
+	}
+	if len(p.Block.Payload) != 0 {
+		t.Fatalf("payload is carried in proof")
+	}
+	if err := p.Verify(gpks[0]); err != nil {
+		t.Fatalf("verify error: %v", err)
+	}
+	if err := p.VerifyNotarySet(records[0].NotarySet()); err != nil {
+		t.Fatalf("verify notary set error: %v", err)
+	}
+	if err := p.VerifyNotarySet(
+		map[coreTypes.NodeID]struct{}{}); err != proof.ErrNotarySetMismatch {
+		t.Fatalf("unexpected error of another notary set: %v", err)
+	}
+
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	decoded, err := proof.Decode(data)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if decoded.Block.Hash != b.Hash {
+		t.Fatalf("decoded block hash mismatch")
+	}
+	if err := decoded.Verify(gpks[0]); err != nil {
+		t.Fatalf("verify decoded error: %v", err)
+	}
+	// The randomness of another block.
+	for _, d := range node.Delivered() {
+		if d.Position.Round == 1 && d.Hash != b.Hash {
+			decoded.Block.Randomness = d.Rand
+			break
+		}
+	}
+	if err := decoded.Verify(gpks[0]); err != proof.ErrIncorrectRandomness {
+		t.Fatalf("unexpected error of tampered randomness: %v", err)
+	}
+	decoded.Block.Timestamp = decoded.Block.Timestamp.Add(time.Second)
+	if err := decoded.Verify(gpks[0]); err != proof.ErrIncorrectBlockHash {
+		t.Fatalf("unexpected error of tampered block: %v", err)
+	}
+
+	// Blocks before DKGDelayRound have no randomness.
+	_, err = node.Consensus.FinalityProof(deliveredTestBlock(t, node, 0).Hash)
+	if err != dexCore.ErrNoFinalityProof {
+		t.Fatalf("unexpected error of block in round 0: %v", err)
+	}
+}
+
+func TestLightClientFinalityProof(t *testing.T) {
+	c, node := newTestCoreCluster(t, 200)
+	defer c.Stop()
+
+	records, err := node.Consensus.ExportGroupPublicKeys(1, 2)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	lc, err := light.New(records[0])
+	if err != nil {
+		t.Fatalf("new light client error: %v", err)
+	}
+	if err := lc.Append(records[0]); err == nil {
+		t.Fatalf("known round is appended")
+	}
+	if err := lc.Append(records[1]); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+	if lc.LatestRound() != 2 {
+		t.Fatalf("unexpected latest round: %d", lc.LatestRound())
+	}
+
+	b := deliveredTestBlock(t, node, 2)
+	p, err := node.Consensus.FinalityProof(b.Hash)
+	if err != nil {
+		t.Fatalf("finality proof error: %v", err)
+	}
+	if err := lc.VerifyFinalityProof(p); err != nil {
+		t.Fatalf("verify finality proof error: %v", err)
+	}
+	if err := lc.VerifyRandomness(b); err != nil {
+		t.Fatalf("verify randomness error: %v", err)
+	}
+	tampered := *p
+	tampered.NotarySetRoot = coreCommon.NewRandomHash()
+	if err := lc.VerifyFinalityProof(
+		&tampered); err != proof.ErrNotarySetMismatch {
+		t.Fatalf("unexpected error of tampered notary set: %v", err)
+	}
+	// The randomness of round 2 is not signed by round 1.
+	b1 := deliveredTestBlock(t, node, 1)
+	b1.Randomness = b.Randomness
+	if err := lc.VerifyRandomness(b1); err != light.ErrIncorrectRandomness {
+		t.Fatalf("unexpected error of randomness of another block: %v", err)
+	}
+
+	lc.Forget(2)
+	if _, err := lc.Round(1); err != light.ErrRoundForgotten {
+		t.Fatalf("unexpected error of forgotten round: %v", err)
+	}
+	if _, err := lc.Round(3); err != light.ErrRoundUnknown {
+		t.Fatalf("unexpected error of unknown round: %v", err)
+	}
+	if err := lc.VerifyFinalityProof(p); err != nil {
+		t.Fatalf("verify finality proof after forgetting error: %v", err)
+	}
+}
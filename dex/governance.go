@@ -63,6 +63,19 @@ func (d *DexconGovernance) DexconConfiguration(round uint64) *params.DexconConfi
 	return d.GetStateForConfigAtRound(round).Configuration()
 }
 
+// IsBlockExtension returns whether core blocks at 'height' carry the
+// extension fields.
+func (d *DexconGovernance) IsBlockExtension(height uint64) bool {
+	return d.chainConfig.IsBlockExtension(new(big.Int).SetUint64(height))
+}
+
+// ManualFinalizationOperators returns operators authorizing manual
+// finalization in the chain config.
+func (d *DexconGovernance) ManualFinalizationOperators(round uint64) (
+	coreTypes.NodeIDs, int) {
+	return core.ManualFinalizationOperators(d.chainConfig)
+}
+
 func (d *DexconGovernance) sendGovTx(ctx context.Context, data []byte) error {
 	gasPrice, err := d.b.SuggestPrice(ctx)
 	if err != nil {
@@ -231,3 +244,16 @@ func (d *DexconGovernance) ResetDKG(newSignedCRS []byte) {
 		log.Error("Failed to send resetDKG tx", "err", err)
 	}
 }
+
+// ReplaceNodePublicKey announces 'publicKey' as the new key of the node
+// owned by the address of the node key.
+func (d *DexconGovernance) ReplaceNodePublicKey(publicKey []byte) error {
+	if d.GetHeadState().NodesOffsetByAddress(d.address).Sign() < 0 {
+		return errNotNodeOwner
+	}
+	data, err := vm.PackReplaceNodePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	return d.sendGovTx(context.Background(), data)
+}
@@ -114,6 +114,11 @@ type ProtocolManager struct {
 	nextPullBlock *sync.Map
 	maxPeers      int
 
+	// The peer limit derived from notary set sizes, zero until derived.
+	notaryPeerLimit  bool
+	notaryPeerMargin int
+	notaryMaxPeers   int32
+
 	downloader *downloader.Downloader
 	fetcher    *fetcher.Fetcher
 	peers      *peerSet
@@ -150,6 +155,9 @@ type ProtocolManager struct {
 	// Dexcon
 	isBlockProposer bool
 	app             dexconApp
+	capabilities    peerCapabilities
+
+	blockPropagation BlockPropagation
 
 	finalizedBlockCh  chan core.NewFinalizedBlockEvent
 	finalizedBlockSub event.Subscription
@@ -186,6 +194,7 @@ func NewProtocolManager(
 		receiveCoreMessage: 0,
 		isBlockProposer:    isBlockProposer,
 		app:                app,
+		capabilities:       defaultPeerCapabilities,
 		blockNumberGauge:   metrics.GetOrRegisterGauge("dex/blocknumber", nil),
 	}
 
@@ -368,7 +377,7 @@ func (pm *ProtocolManager) newPeer(pv int, p *p2p.Peer, rw p2p.MsgReadWriter) *p
 // this function terminates, the peer is disconnected.
 func (pm *ProtocolManager) handle(p *peer) error {
 	// Ignore maxPeers if this is a trusted peer
-	if pm.peers.Len() >= pm.maxPeers && !p.Peer.Info().Network.Trusted {
+	if pm.peers.Len() >= pm.peerLimit() && !p.Peer.Info().Network.Trusted {
 		return p2p.DiscTooManyPeers
 	}
 	p.Log().Debug("Ethereum peer connected", "name", p.Name())
@@ -380,7 +389,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		hash    = head.Hash()
 		number  = head.Number.Uint64()
 	)
-	if err := p.Handshake(pm.networkID, number, hash, genesis.Hash()); err != nil {
+	if err := p.Handshake(pm.networkID, number, hash, genesis.Hash(), pm.capabilities); err != nil {
 		p.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -857,31 +866,66 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			break
 		}
 		var blocks []*coreTypes.Block
-		if err := msg.Decode(&blocks); err != nil {
+		if err := pm.decodeCoreMsg(msg, &blocks); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		pm.cache.addBlocks(blocks)
 		for _, block := range blocks {
+			p.MarkCoreBlock(block.Hash)
 			pm.receiveCh <- coreTypes.Msg{
 				PeerID:  p.ID().String(),
 				Payload: block,
 			}
 		}
+	case p.version >= dex65 && msg.Code == CoreBlockAnnounceMsg:
+		if atomic.LoadInt32(&pm.receiveCoreMessage) == 0 {
+			break
+		}
+		var anns []*coreBlockAnnouncement
+		if err := pm.decodeCoreMsg(msg, &anns); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		var hashes coreCommon.Hashes
+		for _, ann := range anns {
+			p.MarkCoreBlock(ann.Hash)
+			// Blocks below the pruned height are finalized already.
+			if ann.Position.Height < pm.cache.prunedBelow() {
+				continue
+			}
+			hashes = append(hashes, ann.Hash)
+		}
+		// Pull unknown blocks from the announcer.
+		known := make(map[coreCommon.Hash]struct{})
+		for _, block := range pm.cache.blocks(hashes, false) {
+			known[block.Hash] = struct{}{}
+		}
+		unknown := make(coreCommon.Hashes, 0, len(hashes))
+		for _, hash := range hashes {
+			if _, exist := known[hash]; !exist {
+				unknown = append(unknown, hash)
+			}
+		}
+		if len(unknown) > 0 {
+			p.AsyncSendPullBlocks(unknown)
+		}
 	case msg.Code == VoteMsg:
 		if atomic.LoadInt32(&pm.receiveCoreMessage) == 0 {
 			break
 		}
 		var votes []*coreTypes.Vote
-		if err := msg.Decode(&votes); err != nil {
+		if err := pm.decodeCoreMsg(msg, &votes); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		for _, vote := range votes {
 			if vote.Type >= coreTypes.VotePreCom {
 				pm.cache.addVote(vote)
 			}
+		}
+		// Votes are passed in batch, to be verified together.
+		if len(votes) != 0 {
 			pm.receiveCh <- coreTypes.Msg{
 				PeerID:  p.ID().String(),
-				Payload: vote,
+				Payload: votes,
 			}
 		}
 	case msg.Code == AgreementMsg:
@@ -890,7 +934,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		// DKG set is receiver
 		var agreement coreTypes.AgreementResult
-		if err := msg.Decode(&agreement); err != nil {
+		if err := pm.decodeCoreMsg(msg, &agreement); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		p.MarkAgreement(agreement.Position)
@@ -904,13 +948,27 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			PeerID:  p.ID().String(),
 			Payload: &agreement,
 		}
+	case p.version >= dex65 && msg.Code == HeartbeatMsg:
+		if atomic.LoadInt32(&pm.receiveCoreMessage) == 0 {
+			break
+		}
+		var heartbeat coreTypes.Heartbeat
+		if err := pm.decodeCoreMsg(msg, &heartbeat); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		// Consensus core relays it by BroadcastHeartbeat once verified.
+		p.MarkHeartbeat(rlpHash(&heartbeat))
+		pm.receiveCh <- coreTypes.Msg{
+			PeerID:  p.ID().String(),
+			Payload: &heartbeat,
+		}
 	case msg.Code == DKGPrivateShareMsg:
 		if atomic.LoadInt32(&pm.receiveCoreMessage) == 0 {
 			break
 		}
 		// Do not relay this msg
 		var ps dkgTypes.PrivateShare
-		if err := msg.Decode(&ps); err != nil {
+		if err := pm.decodeCoreMsg(msg, &ps); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		p.MarkDKGPrivateShares(rlpHash(ps))
@@ -924,7 +982,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		// broadcast in DKG set
 		var psig dkgTypes.PartialSignature
-		if err := msg.Decode(&psig); err != nil {
+		if err := pm.decodeCoreMsg(msg, &psig); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		pm.receiveCh <- coreTypes.Msg{
@@ -935,19 +993,21 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if atomic.LoadInt32(&pm.receiveCoreMessage) == 0 {
 			break
 		}
-		next, ok := pm.nextPullBlock.Load(p.ID())
-		if ok {
-			nextTime := next.(time.Time)
-			if nextTime.After(time.Now()) {
-				break
-			}
-		}
-		pm.nextPullBlock.Store(p.ID(), time.Now().Add(pullBlockRateLimit))
 		var hashes coreCommon.Hashes
 		if err := msg.Decode(&hashes); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
-		blocks := pm.cache.blocks(hashes, true)
+		// Recent blocks, i.e. those announced, are served from the cache,
+		// only pulls reaching the database are rate limited.
+		blocks := pm.cache.blocks(hashes, false)
+		if len(blocks) < len(hashes) {
+			next, ok := pm.nextPullBlock.Load(p.ID())
+			if !ok || !next.(time.Time).After(time.Now()) {
+				pm.nextPullBlock.Store(p.ID(),
+					time.Now().Add(pullBlockRateLimit))
+				blocks = pm.cache.blocks(hashes, true)
+			}
+		}
 		log.Debug("Push blocks", "blocks", blocks)
 		return p.SendCoreBlocks(blocks)
 	case msg.Code == PullVotesMsg:
@@ -970,9 +1030,25 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			log.Debug("Push finalized block as votes", "block", block)
 			return p.SendCoreBlocks([]*coreTypes.Block{block})
 		}
+		// Peers before dex65 get the empty vote set as before.
+		if below := pm.cache.prunedBelow(); pos.Height < below &&
+			p.version >= dex65 {
+			log.Debug("Votes pruned", "position", pos, "below", below)
+			return p.SendVotesPruned(pos, below)
+		}
 		votes := pm.cache.votes(pos)
 		log.Debug("Push votes", "votes", votes)
 		return p.SendVotes(votes)
+	case p.version >= dex65 && msg.Code == VotesPrunedMsg:
+		var pruned votesPruned
+		if err := pm.decodeCoreMsg(msg, &pruned); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		// Don't pull votes below this height from the peer, those positions
+		// should be synced by finalized blocks instead.
+		p.SetVotesPrunedBelow(pruned.PrunedBelow)
+		p.Log().Debug("Peer pruned votes", "position", pruned.Position,
+			"below", pruned.PrunedBelow)
 	case msg.Code == GetGovStateMsg:
 		var hash common.Hash
 		if err := msg.Decode(&hash); err != nil {
@@ -1124,8 +1200,34 @@ func (pm *ProtocolManager) BroadcastCoreBlock(block *coreTypes.Block) {
 		set:   notaryset,
 		round: block.Position.Round,
 	}
-	for _, peer := range pm.peers.PeersWithLabel(label) {
-		peer.AsyncSendCoreBlocks([]*coreTypes.Block{block})
+	peers := pm.peers.PeersWithLabel(label)
+	if pm.blockPropagation != PushPullPropagation {
+		for _, peer := range peers {
+			peer.AsyncSendCoreBlocks([]*coreTypes.Block{block})
+		}
+		return
+	}
+	// Peers before dex65 can't handle announcements, send them the block.
+	announcees := make([]*peer, 0, len(peers))
+	for _, peer := range peers {
+		if peer.version < dex65 {
+			peer.AsyncSendCoreBlocks([]*coreTypes.Block{block})
+		} else {
+			announcees = append(announcees, peer)
+		}
+	}
+	// Send the block to a subset of peers, and announce it to the rest.
+	transfer := int(math.Sqrt(float64(len(announcees))))
+	if transfer < 1 {
+		transfer = 1
+	}
+	ann := []*coreBlockAnnouncement{newCoreBlockAnnouncement(block)}
+	for idx, peer := range announcees {
+		if idx < transfer {
+			peer.AsyncSendCoreBlocks([]*coreTypes.Block{block})
+		} else {
+			peer.AsyncSendCoreBlockAnnouncements(ann)
+		}
 	}
 }
 
@@ -1209,13 +1311,32 @@ func (pm *ProtocolManager) BroadcastDKGPartialSignature(
 	}
 }
 
+func (pm *ProtocolManager) BroadcastHeartbeat(heartbeat *coreTypes.Heartbeat) {
+	for _, peer := range pm.peers.PeersWithoutHeartbeat(rlpHash(heartbeat)) {
+		peer.AsyncSendHeartbeat(heartbeat)
+	}
+}
+
 func (pm *ProtocolManager) BroadcastPullBlocks(
 	hashes coreCommon.Hashes) {
 	// TODO(jimmy-dexon): pull from notary set only.
-	for idx, peer := range pm.peers.Peers() {
-		if idx >= maxPullPeers {
+	// Peers known to hold the blocks are preferred.
+	var (
+		peers []*peer
+		seen  = make(map[string]struct{})
+	)
+	for _, hash := range hashes {
+		peers = append(peers, pm.peers.PeersWithCoreBlock(hash)...)
+	}
+	peers = append(peers, pm.peers.PeersWithCapabilities(capRelay)...)
+	for _, peer := range peers {
+		if len(seen) >= maxPullPeers {
 			break
 		}
+		if _, exist := seen[peer.id]; exist {
+			continue
+		}
+		seen[peer.id] = struct{}{}
 		peer.AsyncSendPullBlocks(hashes)
 	}
 }
@@ -1226,10 +1347,18 @@ func (pm *ProtocolManager) BroadcastPullVotes(
 		set:   notaryset,
 		round: pos.Round,
 	}
-	for idx, peer := range pm.peers.PeersWithLabel(label) {
+	idx := 0
+	for _, peer := range pm.peers.PeersWithLabel(label) {
+		if !peer.Capabilities().Has(capRelay) {
+			continue
+		}
+		if pos.Height < peer.VotesPrunedBelow() {
+			continue
+		}
 		if idx >= maxPullVotePeers {
 			break
 		}
+		idx++
 		peer.AsyncSendPullVotes(pos)
 	}
 }
@@ -1307,6 +1436,7 @@ func (pm *ProtocolManager) peerSetLoop() {
 		round = CRSRound
 		resetCount = pm.gov.DKGResetCount(round)
 	}
+	pm.updatePeerLimit(round)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1363,12 +1493,47 @@ func (pm *ProtocolManager) peerSetLoop() {
 			}
 			round = newRound
 			resetCount = reset
+			pm.updatePeerLimit(round)
 		case <-pm.chainHeadSub.Err():
 			return
 		}
 	}
 }
 
+// peerLimit returns the maximum count of peers, which follows sizes of notary
+// sets once derived.
+func (pm *ProtocolManager) peerLimit() int {
+	if limit := atomic.LoadInt32(&pm.notaryMaxPeers); limit > 0 {
+		return int(limit)
+	}
+	return pm.maxPeers
+}
+
+// updatePeerLimit derives the peer limit from sizes of notary sets of 'round'
+// and the next round plus the margin, so small deployments aren't overwhelmed
+// and large ones keep enough direct paths to propagate votes.
+func (pm *ProtocolManager) updatePeerLimit(round uint64) {
+	if !pm.notaryPeerLimit {
+		return
+	}
+	size := 0
+	for _, r := range []uint64{round, round + 1} {
+		notarySet, err := pm.gov.NotarySet(r)
+		if err != nil {
+			continue
+		}
+		if len(notarySet) > size {
+			size = len(notarySet)
+		}
+	}
+	if size == 0 {
+		return
+	}
+	limit := size + pm.notaryPeerMargin
+	log.Debug("Update peer limit", "round", round, "limit", limit)
+	atomic.StoreInt32(&pm.notaryMaxPeers, int32(limit))
+}
+
 // NodeInfo represents a short summary of the Ethereum sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
@@ -444,3 +444,40 @@ func testGetReceipt(t *testing.T, protocol int) {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that the peer limit follows the larger of the current and next
+// notary sets plus the configured margin.
+func TestNotaryPeerLimit(t *testing.T) {
+	sizes := map[uint64]int{1: 4, 2: 7}
+	pm := &ProtocolManager{
+		maxPeers: 25,
+		gov: &testGovernance{
+			notarySetFunc: func(round uint64) (map[string]struct{}, error) {
+				set := make(map[string]struct{})
+				for i := 0; i < sizes[round]; i++ {
+					set[string(rune('a'+i))] = struct{}{}
+				}
+				return set, nil
+			},
+		},
+	}
+	pm.updatePeerLimit(1)
+	if limit := pm.peerLimit(); limit != 25 {
+		t.Errorf("peer limit mismatch when disabled: have %d, want %d", limit, 25)
+	}
+	pm.notaryPeerLimit = true
+	pm.notaryPeerMargin = 3
+	pm.updatePeerLimit(1)
+	if limit := pm.peerLimit(); limit != 10 {
+		t.Errorf("peer limit mismatch: have %d, want %d", limit, 10)
+	}
+	pm.updatePeerLimit(2)
+	if limit := pm.peerLimit(); limit != 10 {
+		t.Errorf("peer limit mismatch: have %d, want %d", limit, 10)
+	}
+	// Keep the last derived limit when notary sets are unknown.
+	pm.updatePeerLimit(5)
+	if limit := pm.peerLimit(); limit != 10 {
+		t.Errorf("peer limit mismatch: have %d, want %d", limit, 10)
+	}
+}
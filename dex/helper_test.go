@@ -287,13 +287,23 @@ func newTestPeer(name string, version int, pm *ProtocolManager, shake bool) (*te
 // handshake simulates a trivial handshake that expects the same state from the
 // remote side as we are simulating locally.
 func (p *testPeer) handshake(t *testing.T, number uint64, head common.Hash, genesis common.Hash) {
-	msg := &statusData{
+	var msg interface{} = &statusData{
 		ProtocolVersion: uint32(p.version),
 		NetworkId:       DefaultConfig.NetworkId,
 		Number:          number,
 		CurrentBlock:    head,
 		GenesisBlock:    genesis,
 	}
+	if p.version >= dex65 {
+		msg = &statusData65{
+			ProtocolVersion: uint32(p.version),
+			NetworkId:       DefaultConfig.NetworkId,
+			Number:          number,
+			CurrentBlock:    head,
+			GenesisBlock:    genesis,
+			Capabilities:    defaultPeerCapabilities,
+		}
+	}
 	if err := p2p.ExpectMsg(p.app, StatusMsg, msg); err != nil {
 		t.Fatalf("status recv: %v", err)
 	}
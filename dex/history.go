@@ -0,0 +1,178 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
+	coreCrypto "github.com/dexon-foundation/dexon-consensus/core/crypto"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+
+	"github.com/dexon-foundation/dexon/core"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/params"
+	"github.com/dexon-foundation/dexon/rlp"
+)
+
+// Errors for history verification.
+var (
+	errHistoryVerifying = errors.New("history verification is running")
+	errPayloadMismatch  = errors.New("transactions mismatch payload hash")
+)
+
+// chainHistory provides finalized core blocks kept in the headers of the
+// local chain to dexCore.HistoryVerifier.
+type chainHistory struct {
+	chain *core.BlockChain
+}
+
+// NewChainHistory returns the finalized history of 'chain'.
+func NewChainHistory(chain *core.BlockChain) dexCore.HistorySource {
+	return &chainHistory{chain: chain}
+}
+
+func (h *chainHistory) TipHeight() uint64 {
+	return h.chain.CurrentBlock().NumberU64()
+}
+
+// BlockAt decodes the core block at 'height', its payload is stripped when
+// delivered, so it's rebuilt from transactions of the chain block.
+func (h *chainHistory) BlockAt(height uint64) (*coreTypes.Block, error) {
+	block := h.chain.GetBlockByNumber(height)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	var b coreTypes.Block
+	if err := rlp.DecodeBytes(block.Header().DexconMeta, &b); err != nil {
+		return nil, err
+	}
+	if b.IsEmpty() {
+		return &b, nil
+	}
+	txs := block.Transactions()
+	payload, err := rlp.EncodeToBytes(&txs)
+	if err != nil {
+		return nil, err
+	}
+	if coreCrypto.Keccak256Hash(payload) != b.PayloadHash {
+		// A proposer might give up preparing payload in time, it's nil
+		// rather than an empty list then.
+		if len(txs) > 0 {
+			return nil, errPayloadMismatch
+		}
+		payload = nil
+	}
+	b.Payload = payload
+	return &b, nil
+}
+
+// historyGovernance is the governance of the local chain to verify its
+// history, block extension and manual finalization operators are defined by
+// the chain config.
+type historyGovernance struct {
+	*core.Governance
+	config *params.ChainConfig
+}
+
+// NewHistoryGovernance returns the governance to verify the history of
+// 'chain' without running a node.
+func NewHistoryGovernance(chain *core.BlockChain) dexCore.HistoryGovernance {
+	return &historyGovernance{
+		Governance: core.NewGovernance(core.NewGovernanceStateDB(chain)),
+		config:     chain.Config(),
+	}
+}
+
+// IsBlockExtension returns whether core blocks at 'height' carry the
+// extension fields.
+func (g *historyGovernance) IsBlockExtension(height uint64) bool {
+	return g.config.IsBlockExtension(new(big.Int).SetUint64(height))
+}
+
+// ManualFinalizationOperators returns operators authorizing manual
+// finalization in the chain config.
+func (g *historyGovernance) ManualFinalizationOperators(round uint64) (
+	coreTypes.NodeIDs, int) {
+	return core.ManualFinalizationOperators(g.config)
+}
+
+// historyVerification runs at most one history verification at a time in
+// background, and keeps the last one for its progress.
+type historyVerification struct {
+	lock     sync.Mutex
+	verifier *dexCore.HistoryVerifier
+	running  bool
+}
+
+func (v *historyVerification) start(dex *Dexon, from uint64) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.running {
+		return errHistoryVerifying
+	}
+	v.verifier = dexCore.NewHistoryVerifier(dex.governance,
+		NewChainHistory(dex.blockchain), log.Root())
+	v.running = true
+	go func(verifier *dexCore.HistoryVerifier) {
+		if err := verifier.Verify(context.Background(), from); err != nil {
+			log.Error("History verification failed", "err", err)
+		}
+		v.lock.Lock()
+		defer v.lock.Unlock()
+		v.running = false
+	}(v.verifier)
+	return nil
+}
+
+// HistoryVerificationStatus is the progress of a history verification
+// reported by admin API.
+type HistoryVerificationStatus struct {
+	From     uint64 `json:"from"`
+	To       uint64 `json:"to"`
+	Verified uint64 `json:"verified"`
+	Round    uint64 `json:"round"`
+	Elapsed  string `json:"elapsed"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (v *historyVerification) status() *HistoryVerificationStatus {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.verifier == nil {
+		return nil
+	}
+	p := v.verifier.Progress()
+	s := &HistoryVerificationStatus{
+		From:     p.From,
+		To:       p.To,
+		Verified: p.Verified,
+		Round:    p.Round,
+		Elapsed:  p.Elapsed.String(),
+		Done:     p.Done,
+	}
+	if p.Err != nil {
+		s.Error = p.Err.Error()
+	}
+	return s
+}
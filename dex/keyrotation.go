@@ -0,0 +1,95 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"errors"
+	"sync"
+
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+
+	"github.com/dexon-foundation/dexon/log"
+)
+
+// Errors for node key rotation.
+var (
+	errNotNodeOwner = errors.New(
+		"node key is not the owner of a registered node")
+	errRotationInProgress  = errors.New("node key rotation in progress")
+	errGraceWindowTooShort = errors.New(
+		"grace window shorter than config round shift")
+)
+
+// nodeKeyRotation tracks the node key being rotated by this process. The
+// consensus core keeps running with the previous key, the node should be
+// restarted with the new key once the grace window ends.
+type nodeKeyRotation struct {
+	lock     sync.Mutex
+	rotation *keystore.Rotation
+	notified bool
+}
+
+// rotate generates a new node key to replace the current one and announces
+// it to governance. The previous key is kept valid for 'graceRounds' rounds,
+// which should cover the rounds whose node sets are built before the
+// announcement. The rotation is saved by 'persist' before announced, to not
+// lose the new key.
+func (r *nodeKeyRotation) rotate(dex *Dexon, graceRounds uint64,
+	persist func(*keystore.Rotation) error) (*keystore.Rotation, error) {
+	if graceRounds <= dexCore.ConfigRoundShift {
+		return nil, errGraceWindowTooShort
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	round := dex.governance.Round()
+	if r.rotation != nil && round < r.rotation.EffectiveRound {
+		return nil, errRotationInProgress
+	}
+	prv := coreEcdsa.NewPrivateKeyFromECDSA(dex.config.PrivateKey)
+	rotation, err := keystore.NewRotation(prv, round, graceRounds)
+	if err != nil {
+		return nil, err
+	}
+	if err = persist(rotation); err != nil {
+		return nil, err
+	}
+	err = dex.governance.ReplaceNodePublicKey(
+		rotation.Current.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Announced new node key",
+		"round", round, "effective", rotation.EffectiveRound)
+	r.rotation = rotation
+	r.notified = false
+	return rotation, nil
+}
+
+// check warns once the grace window of the rotation ends in 'round'.
+func (r *nodeKeyRotation) check(round uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.rotation == nil || r.notified || round < r.rotation.EffectiveRound {
+		return
+	}
+	r.notified = true
+	log.Warn("Grace window of node key rotation ended, restart with new key",
+		"round", round, "effective", r.rotation.EffectiveRound)
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	coreDKG "github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/keystore"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+func newTestKeystoreKey(t *testing.T) *coreEcdsa.PrivateKey {
+	prv, err := coreEcdsa.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("new private key error: %v", err)
+	}
+	return prv
+}
+
+func TestKeystoreECDSARoundTrip(t *testing.T) {
+	prv := newTestKeystoreKey(t)
+	data, err := keystore.ExportECDSA(prv, "passphrase",
+		keystore.LightArgon2Time, keystore.LightArgon2Memory)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if bytes.Contains(data, prv.Bytes()) {
+		t.Fatalf("key is exported in plaintext")
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if fields["kdf"] != "argon2id" {
+		t.Fatalf("unexpected kdf: %v", fields["kdf"])
+	}
+	if fields["id"] != coreTypes.NewNodeID(prv.PublicKey()).Hash.String() {
+		t.Fatalf("unexpected id: %v", fields["id"])
+	}
+	if kind, err := keystore.Kind(data); err != nil ||
+		kind != keystore.KindECDSA {
+		t.Fatalf("unexpected kind: %v %v", kind, err)
+	}
+	got, err := keystore.ImportECDSA(data, "passphrase")
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), prv.Bytes()) {
+		t.Fatalf("imported key mismatch")
+	}
+}
+
+func TestKeystoreDKGRoundTrip(t *testing.T) {
+	prv := coreDKG.NewPrivateKey()
+	data, err := keystore.ExportDKG(prv, "passphrase",
+		keystore.LightArgon2Time, keystore.LightArgon2Memory)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	got, err := keystore.ImportDKG(data, "passphrase")
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), prv.Bytes()) {
+		t.Fatalf("imported key mismatch")
+	}
+	if _, err := keystore.ImportECDSA(data, "passphrase"); err !=
+		keystore.ErrKindMismatch {
+		t.Fatalf("unexpected error of importing dkg key as ecdsa: %v", err)
+	}
+}
+
+func TestKeystoreWrongPassphrase(t *testing.T) {
+	prv := newTestKeystoreKey(t)
+	data, err := keystore.ExportECDSA(prv, "passphrase",
+		keystore.LightArgon2Time, keystore.LightArgon2Memory)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if _, err := keystore.ImportECDSA(data, "wrong"); err !=
+		keystore.ErrDecrypt {
+		t.Fatalf("unexpected error of wrong passphrase: %v", err)
+	}
+	if _, err := keystore.ChangePassphrase(data, "wrong", "new",
+		keystore.LightArgon2Time,
+		keystore.LightArgon2Memory); err != keystore.ErrDecrypt {
+		t.Fatalf("unexpected error of changing with wrong passphrase: %v",
+			err)
+	}
+
+	// The kind is authenticated along with the key.
+	tampered := bytes.Replace(data, []byte(`"kind": "ecdsa"`),
+		[]byte(`"kind": "dkg"`), 1)
+	if _, err := keystore.ImportDKG(tampered, "passphrase"); err !=
+		keystore.ErrDecrypt {
+		t.Fatalf("unexpected error of tampered kind: %v", err)
+	}
+}
+
+func TestKeystoreChangePassphrase(t *testing.T) {
+	prv := newTestKeystoreKey(t)
+	data, err := keystore.ExportECDSA(prv, "passphrase",
+		keystore.LightArgon2Time, keystore.LightArgon2Memory)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	changed, err := keystore.ChangePassphrase(data, "passphrase", "new",
+		keystore.LightArgon2Time, keystore.LightArgon2Memory)
+	if err != nil {
+		t.Fatalf("change passphrase error: %v", err)
+	}
+	if _, err := keystore.ImportECDSA(changed, "passphrase"); err !=
+		keystore.ErrDecrypt {
+		t.Fatalf("unexpected error of previous passphrase: %v", err)
+	}
+	got, err := keystore.ImportECDSA(changed, "new")
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), prv.Bytes()) {
+		t.Fatalf("imported key mismatch")
+	}
+}
+
+func TestKeystoreRotation(t *testing.T) {
+	prv := newTestKeystoreKey(t)
+	rotation, err := keystore.NewRotation(prv, 5, 3)
+	if err != nil {
+		t.Fatalf("new rotation error: %v", err)
+	}
+	if rotation.EffectiveRound != 8 {
+		t.Fatalf("unexpected effective round: %d", rotation.EffectiveRound)
+	}
+	if rotation.KeyAt(7) != prv || rotation.KeyAt(8) != rotation.Current {
+		t.Fatalf("unexpected key in grace window")
+	}
+
+	data, err := keystore.ExportRotation(rotation, "passphrase",
+		keystore.LightArgon2Time, keystore.LightArgon2Memory)
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if _, err := keystore.ImportRotation(data, "wrong"); err !=
+		keystore.ErrDecrypt {
+		t.Fatalf("unexpected error of wrong passphrase: %v", err)
+	}
+	if _, err := keystore.ImportECDSA(data, "passphrase"); err !=
+		keystore.ErrKindMismatch {
+		t.Fatalf("unexpected error of importing rotation as ecdsa: %v", err)
+	}
+	got, err := keystore.ImportRotation(data, "passphrase")
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if !bytes.Equal(got.Previous.Bytes(), prv.Bytes()) ||
+		!bytes.Equal(got.Current.Bytes(), rotation.Current.Bytes()) {
+		t.Fatalf("imported keys mismatch")
+	}
+	if got.AnnouncedRound != 5 || got.EffectiveRound != 8 {
+		t.Fatalf("unexpected rounds: %d %d",
+			got.AnnouncedRound, got.EffectiveRound)
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
+	coreCrypto "github.com/dexon-foundation/dexon-consensus/core/crypto"
+	coreDKG "github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	coreDb "github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/light"
+	"github.com/dexon-foundation/dexon-consensus/core/proof"
+	"github.com/dexon-foundation/dexon-consensus/core/syncer"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+	dkgTypes "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
+	coreUtils "github.com/dexon-foundation/dexon-consensus/core/utils"
+
+	"github.com/dexon-foundation/dexon/common/hexutil"
+	"github.com/dexon-foundation/dexon/core"
+	"github.com/dexon-foundation/dexon/log"
+	"github.com/dexon-foundation/dexon/params"
+)
+
+// newTestManualFinalizationConfig returns a chain config with 'n' manual
+// finalization operators, and their keys.
+func newTestManualFinalizationConfig(t *testing.T, n, threshold int) (
+	*params.ChainConfig, []coreCrypto.PrivateKey) {
+	config := *params.TestnetChainConfig
+	config.ManualFinalization = &params.ManualFinalizationConfig{
+		Threshold: threshold,
+	}
+	keys := make([]coreCrypto.PrivateKey, n)
+	for i := range keys {
+		key, err := coreEcdsa.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("new private key error: %v", err)
+		}
+		keys[i] = key
+		config.ManualFinalization.Operators = append(
+			config.ManualFinalization.Operators,
+			hexutil.Bytes(key.PublicKey().Bytes()))
+	}
+	return &config, keys
+}
+
+func signTestManualFinalization(t *testing.T, m *coreTypes.ManualFinalization,
+	keys ...coreCrypto.PrivateKey) {
+	hash := coreUtils.HashManualFinalization(m)
+	for _, key := range keys {
+		sig, err := key.Sign(hash)
+		if err != nil {
+			t.Fatalf("sign manual finalization error: %v", err)
+		}
+		m.Signatures = append(m.Signatures, sig)
+	}
+}
+
+func newTestManualFinalizationRandomness(t *testing.T,
+	m *coreTypes.ManualFinalization) []byte {
+	rand, err := coreTypes.ManualFinalizationRandomness(m)
+	if err != nil {
+		t.Fatalf("manual finalization randomness error: %v", err)
+	}
+	return rand
+}
+
+func TestManualFinalizationOperators(t *testing.T) {
+	config, keys := newTestManualFinalizationConfig(t, 3, 2)
+	config.ManualFinalization.Operators = append(
+		config.ManualFinalization.Operators, hexutil.Bytes{0x1, 0x2})
+
+	gov := &DexconGovernance{chainConfig: config}
+	operators, threshold := gov.ManualFinalizationOperators(1)
+	if threshold != 2 {
+		t.Fatalf("threshold mismatch: %d", threshold)
+	}
+	if len(operators) != len(keys) {
+		t.Fatalf("invalid operator should be skipped: %d", len(operators))
+	}
+	for i, key := range keys {
+		if operators[i] != coreTypes.NewNodeID(key.PublicKey()) {
+			t.Fatalf("operator %d mismatch", i)
+		}
+	}
+
+	gov = &DexconGovernance{chainConfig: params.TestnetChainConfig}
+	if operators, threshold = gov.ManualFinalizationOperators(1); len(operators) != 0 ||
+		threshold != 0 {
+		t.Fatalf("manual finalization should be disabled by default")
+	}
+}
+
+func TestManualFinalizationRandomness(t *testing.T) {
+	_, keys := newTestManualFinalizationConfig(t, 1, 1)
+	m := &coreTypes.ManualFinalization{
+		Position:  coreTypes.Position{Round: 2, Height: 300},
+		BlockHash: coreCommon.NewRandomHash(),
+		Reason:    "dkg failed",
+	}
+	signTestManualFinalization(t, m, keys...)
+	rand := newTestManualFinalizationRandomness(t, m)
+	if !coreTypes.IsManuallyFinalized(&coreTypes.Block{Randomness: rand}) {
+		t.Fatalf("randomness should carry the marker")
+	}
+	decoded, err := coreTypes.DecodeManualFinalization(rand)
+	if err != nil {
+		t.Fatalf("decode manual finalization error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, m) {
+		t.Fatalf("manual finalization mismatch: %v != %v", decoded, m)
+	}
+	if _, err = coreTypes.DecodeManualFinalization(
+		[]byte{0x1, 0x2}); err != coreTypes.ErrNotManuallyFinalized {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestVerifyManualFinalization(t *testing.T) {
+	config, keys := newTestManualFinalizationConfig(t, 3, 2)
+	gov := &historyGovernance{config: config}
+	outsider, err := coreEcdsa.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("new private key error: %v", err)
+	}
+	pos := coreTypes.Position{Round: 2, Height: 300}
+	hash := coreCommon.NewRandomHash()
+
+	newRand := func(pos coreTypes.Position, hash coreCommon.Hash,
+		signers ...coreCrypto.PrivateKey) []byte {
+		m := &coreTypes.ManualFinalization{
+			Position:  pos,
+			BlockHash: hash,
+			Reason:    "dkg failed",
+		}
+		signTestManualFinalization(t, m, signers...)
+		return newTestManualFinalizationRandomness(t, m)
+	}
+
+	if err := dexCore.VerifyManualFinalization(gov, pos, hash, false,
+		newRand(pos, hash, keys[0], keys[2])); err != nil {
+		t.Fatalf("verify manual finalization error: %v", err)
+	}
+	for name, rand := range map[string][]byte{
+		"too few operators":   newRand(pos, hash, keys[0]),
+		"duplicated operator": newRand(pos, hash, keys[0], keys[0]),
+		"outsider":            newRand(pos, hash, keys[1], outsider),
+	} {
+		if err := dexCore.VerifyManualFinalization(
+			gov, pos, hash, false, rand); err == nil {
+			t.Fatalf("manual finalization signed by %s should fail", name)
+		}
+	}
+
+	rand := newRand(pos, hash, keys...)
+	if err := dexCore.VerifyManualFinalization(gov, pos,
+		coreCommon.NewRandomHash(), false,
+		rand); err != dexCore.ErrManualFinalizationBlockMismatch {
+		t.Fatalf("unexpected error of another block: %v", err)
+	}
+	other := pos
+	other.Height++
+	if err := dexCore.VerifyManualFinalization(gov, other, hash, false,
+		rand); err != dexCore.ErrManualFinalizationBlockMismatch {
+		t.Fatalf("unexpected error of another position: %v", err)
+	}
+
+	// A finalization without block hash finalizes an empty block only.
+	rand = newRand(pos, coreCommon.Hash{}, keys...)
+	if err := dexCore.VerifyManualFinalization(
+		gov, pos, hash, true, rand); err != nil {
+		t.Fatalf("verify empty block finalization error: %v", err)
+	}
+	if err := dexCore.VerifyManualFinalization(gov, pos, hash, false,
+		rand); err != dexCore.ErrManualFinalizationBlockMismatch {
+		t.Fatalf("unexpected error of non-empty block: %v", err)
+	}
+
+	early := coreTypes.Position{Round: dexCore.DKGDelayRound - 1, Height: 10}
+	if err := dexCore.VerifyManualFinalization(gov, early, hash, false,
+		newRand(early, hash, keys...)); err !=
+		dexCore.ErrManualFinalizationRoundTooEarly {
+		t.Fatalf("unexpected error before DKG delay round: %v", err)
+	}
+
+	disabled := &historyGovernance{config: params.TestnetChainConfig}
+	if err := dexCore.VerifyManualFinalization(disabled, pos, hash, false,
+		newRand(pos, hash, keys...)); err !=
+		dexCore.ErrManualFinalizationDisabled {
+		t.Fatalf("unexpected error when disabled: %v", err)
+	}
+	if err := dexCore.VerifyManualFinalization(gov, pos, hash, false,
+		[]byte{0x1}); err != coreTypes.ErrNotManuallyFinalized {
+		t.Fatalf("unexpected error without marker: %v", err)
+	}
+}
+
+// testBackfillGovernance is the governance of backfill verification, only
+// manual finalization operators are provided.
+type testBackfillGovernance struct {
+	dexCore.Governance
+	*historyGovernance
+}
+
+func TestBackfillManualFinalization(t *testing.T) {
+	config, keys := newTestManualFinalizationConfig(t, 3, 2)
+	gov := &testBackfillGovernance{
+		historyGovernance: &historyGovernance{config: config},
+	}
+	proposer, err := coreEcdsa.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("new private key error: %v", err)
+	}
+	genesis := &coreTypes.Block{
+		Position:   coreTypes.Position{Height: coreTypes.GenesisHeight},
+		Timestamp:  time.Now().UTC(),
+		Randomness: dexCore.NoRand,
+	}
+	if err := coreUtils.NewSigner(proposer).SignBlock(genesis); err != nil {
+		t.Fatalf("sign block error: %v", err)
+	}
+
+	newChain := func(signers ...coreCrypto.PrivateKey) *coreTypes.Block {
+		b := &coreTypes.Block{
+			ParentHash: genesis.Hash,
+			Position: coreTypes.Position{
+				Round:  dexCore.DKGDelayRound,
+				Height: coreTypes.GenesisHeight + 1,
+			},
+			Timestamp: genesis.Timestamp.Add(time.Second),
+		}
+		b.Hash, err = coreUtils.HashBlock(b)
+		if err != nil {
+			t.Fatalf("hash block error: %v", err)
+		}
+		m := &coreTypes.ManualFinalization{
+			Position: b.Position,
+			Reason:   "dkg failed",
+		}
+		signTestManualFinalization(t, m, signers...)
+		b.Randomness = newTestManualFinalizationRandomness(t, m)
+		return b
+	}
+
+	verify := func(checkpoint *coreTypes.Block) syncer.BackfillStatus {
+		dbInst, err := coreDb.NewMemBackedDB()
+		if err != nil {
+			t.Fatalf("new db error: %v", err)
+		}
+		for _, b := range []*coreTypes.Block{genesis, checkpoint} {
+			if err := dbInst.PutBlock(*b); err != nil {
+				t.Fatalf("put block error: %v", err)
+			}
+		}
+		v, err := syncer.NewBackfillVerifier(checkpoint.Hash, 1000, gov,
+			dbInst, nil, log.Root())
+		if err != nil {
+			t.Fatalf("new backfill verifier error: %v", err)
+		}
+		v.Start()
+		defer v.Stop()
+		for i := 0; i < 100; i++ {
+			if status := v.Status(); status.Err != nil ||
+				status.Level == syncer.TrustFullArchive {
+				return status
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("backfill verification timeout")
+		return syncer.BackfillStatus{}
+	}
+
+	if status := verify(newChain(keys[0], keys[1])); status.Err != nil {
+		t.Fatalf("backfill verification error: %v", status.Err)
+	}
+	if status := verify(newChain(keys[0])); status.Err == nil {
+		t.Fatalf("finalization signed by too few operators should fail")
+	}
+}
+
+// newTestGroupPublicKeyRecord returns a record of 'round' whose DKG is done by
+// 'n' nodes, and keys of those nodes.
+func newTestGroupPublicKeyRecord(t *testing.T, round uint64, n int) (
+	*dexCore.GroupPublicKeyRecord, []coreCrypto.PrivateKey) {
+	r := &dexCore.GroupPublicKeyRecord{
+		Round:         round,
+		CRS:           coreCommon.NewRandomHash(),
+		NotarySetSize: uint32(n),
+	}
+	keys := make([]coreCrypto.PrivateKey, n)
+	threshold := coreUtils.GetDKGThreshold(
+		&coreTypes.Config{NotarySetSize: uint32(n)})
+	for i := range keys {
+		key, err := coreEcdsa.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("new private key error: %v", err)
+		}
+		keys[i] = key
+		nID := coreTypes.NewNodeID(key.PublicKey())
+		r.NodeSet = append(r.NodeSet, nID)
+		_, pubShares := coreDKG.NewPrivateKeyShares(threshold)
+		mpk := &dkgTypes.MasterPublicKey{
+			Round:           round,
+			DKGID:           dkgTypes.NewID(nID),
+			PublicKeyShares: *pubShares.Move(),
+		}
+		if err := coreUtils.NewSigner(key).SignDKGMasterPublicKey(
+			mpk); err != nil {
+			t.Fatalf("sign master public key error: %v", err)
+		}
+		r.MasterPublicKeys = append(r.MasterPublicKeys, mpk)
+	}
+	return r, keys
+}
+
+func TestLightClientManualFinalization(t *testing.T) {
+	config, keys := newTestManualFinalizationConfig(t, 3, 2)
+	record, nodeKeys := newTestGroupPublicKeyRecord(t, 2, 4)
+	c, err := light.New(record)
+	if err != nil {
+		t.Fatalf("new light client error: %v", err)
+	}
+	b := &coreTypes.Block{
+		ParentHash: coreCommon.NewRandomHash(),
+		Position:   coreTypes.Position{Round: record.Round, Height: 300},
+		Timestamp:  time.Now().UTC(),
+	}
+	if err := coreUtils.NewSigner(nodeKeys[0]).SignBlock(b); err != nil {
+		t.Fatalf("sign block error: %v", err)
+	}
+	m := &coreTypes.ManualFinalization{
+		Position:  b.Position,
+		BlockHash: b.Hash,
+		Reason:    "dkg failed",
+	}
+	signTestManualFinalization(t, m, keys[1], keys[2])
+	b.Randomness = newTestManualFinalizationRandomness(t, m)
+	p, err := proof.New(b, record.NotarySet())
+	if err != nil {
+		t.Fatalf("new finality proof error: %v", err)
+	}
+
+	// Operators are unknown to the client.
+	if err := c.VerifyRandomness(b); err !=
+		dexCore.ErrManualFinalizationDisabled {
+		t.Fatalf("unexpected error without operators: %v", err)
+	}
+	if err := c.VerifyFinalityProof(p); err == nil {
+		t.Fatalf("finality proof should fail without operators")
+	}
+
+	operators, threshold := core.ManualFinalizationOperators(config)
+	c.SetManualFinalizationOperators(operators, threshold)
+	if err := c.VerifyRandomness(b); err != nil {
+		t.Fatalf("verify randomness error: %v", err)
+	}
+	if err := c.VerifyFinalityProof(p); err != nil {
+		t.Fatalf("verify finality proof error: %v", err)
+	}
+
+	// The finalization of another block is rejected.
+	forged := *b
+	forged.Payload = []byte{0x1}
+	if err := coreUtils.NewSigner(nodeKeys[0]).SignBlock(&forged); err != nil {
+		t.Fatalf("sign block error: %v", err)
+	}
+	if err := c.VerifyRandomness(&forged); err !=
+		dexCore.ErrManualFinalizationBlockMismatch {
+		t.Fatalf("unexpected error of another block: %v", err)
+	}
+}
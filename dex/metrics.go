@@ -17,10 +17,44 @@
 package dex
 
 import (
+	"time"
+
+	dexCore "github.com/dexon-foundation/dexon-consensus/core"
 	"github.com/dexon-foundation/dexon/metrics"
 	"github.com/dexon-foundation/dexon/p2p"
 )
 
+// nodeHeightMetricsInterval is the interval to update metrics of finalized
+// heights reported by nodes.
+const nodeHeightMetricsInterval = 30 * time.Second
+
+var (
+	nodeHeightMaxLagGauge  = metrics.NewRegisteredGauge("dex/consensus/height/maxlag", nil)
+	nodeHeightLaggingGauge = metrics.NewRegisteredGauge("dex/consensus/height/lagging", nil)
+	nodeHeightStalledGauge = metrics.NewRegisteredGauge("dex/consensus/height/stalled", nil)
+)
+
+// updateNodeHeightMetrics updates metrics from finalized heights reported by
+// nodes, to tell how many nodes are holding back the network.
+func updateNodeHeightMetrics(heights []dexCore.NodeHeight) {
+	var maxLag uint64
+	var lagging, stalled int64
+	for _, h := range heights {
+		if h.Lag > maxLag {
+			maxLag = h.Lag
+		}
+		if h.Lag > 0 {
+			lagging++
+		}
+		if h.Stalled {
+			stalled++
+		}
+	}
+	nodeHeightMaxLagGauge.Update(int64(maxLag))
+	nodeHeightLaggingGauge.Update(lagging)
+	nodeHeightStalledGauge.Update(stalled)
+}
+
 var (
 	propBlockConfirmLatency                = metrics.NewRegisteredGauge("dex/prop/blockconfirm/latency", nil)
 	propTxnInPacketsMeter                  = metrics.NewRegisteredMeter("dex/prop/txns/in/packets", nil)
@@ -39,6 +73,10 @@ var (
 	propCoreBlockInTrafficMeter            = metrics.NewRegisteredMeter("dex/prop/coreblocks/in/traffic", nil)
 	propCoreBlockOutPacketsMeter           = metrics.NewRegisteredMeter("dex/prop/coreblocks/out/packets", nil)
 	propCoreBlockOutTrafficMeter           = metrics.NewRegisteredMeter("dex/prop/coreblocks/out/traffic", nil)
+	propCoreBlockAnnInPacketsMeter         = metrics.NewRegisteredMeter("dex/prop/coreblockanns/in/packets", nil)
+	propCoreBlockAnnInTrafficMeter         = metrics.NewRegisteredMeter("dex/prop/coreblockanns/in/traffic", nil)
+	propCoreBlockAnnOutPacketsMeter        = metrics.NewRegisteredMeter("dex/prop/coreblockanns/out/packets", nil)
+	propCoreBlockAnnOutTrafficMeter        = metrics.NewRegisteredMeter("dex/prop/coreblockanns/out/traffic", nil)
 	propVoteInPacketsMeter                 = metrics.NewRegisteredMeter("dex/prop/votes/in/packets", nil)
 	propVoteInTrafficMeter                 = metrics.NewRegisteredMeter("dex/prop/votes/in/traffic", nil)
 	propVoteOutPacketsMeter                = metrics.NewRegisteredMeter("dex/prop/votes/out/packets", nil)
@@ -131,6 +169,8 @@ func (rw *meteredMsgReadWriter) ReadMsg() (p2p.Msg, error) {
 
 	case msg.Code == CoreBlockMsg:
 		packets, traffic = propCoreBlockInPacketsMeter, propCoreBlockInTrafficMeter
+	case msg.Code == CoreBlockAnnounceMsg:
+		packets, traffic = propCoreBlockAnnInPacketsMeter, propCoreBlockAnnInTrafficMeter
 	case msg.Code == VoteMsg:
 		packets, traffic = propVoteInPacketsMeter, propVoteInTrafficMeter
 
@@ -173,6 +213,8 @@ func (rw *meteredMsgReadWriter) WriteMsg(msg p2p.Msg) error {
 
 	case msg.Code == CoreBlockMsg:
 		packets, traffic = propCoreBlockOutPacketsMeter, propCoreBlockOutTrafficMeter
+	case msg.Code == CoreBlockAnnounceMsg:
+		packets, traffic = propCoreBlockAnnOutPacketsMeter, propCoreBlockAnnOutTrafficMeter
 	case msg.Code == VoteMsg:
 		packets, traffic = propVoteOutPacketsMeter, propVoteOutTrafficMeter
 
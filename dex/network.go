@@ -20,6 +20,7 @@ package dex
 import (
 	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
 	"github.com/dexon-foundation/dexon-consensus/core/crypto"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
 	"github.com/dexon-foundation/dexon-consensus/core/types"
 	dkgTypes "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
 )
@@ -83,6 +84,11 @@ func (n *DexconNetwork) BroadcastAgreementResult(result *types.AgreementResult)
 	n.pm.BroadcastAgreementResult(result)
 }
 
+// BroadcastHeartbeat gossips heartbeat to all peers.
+func (n *DexconNetwork) BroadcastHeartbeat(heartbeat *types.Heartbeat) {
+	n.pm.BroadcastHeartbeat(heartbeat)
+}
+
 // ReceiveChan returns a channel to receive messages from DEXON network.
 func (n *DexconNetwork) ReceiveChan() <-chan types.Msg {
 	return n.pm.ReceiveChan()
@@ -92,3 +98,16 @@ func (n *DexconNetwork) ReceiveChan() <-chan types.Msg {
 func (n *DexconNetwork) ReportBadPeerChan() chan<- interface{} {
 	return n.pm.ReportBadPeerChan()
 }
+
+// PeerNodeID returns the consensus node ID of a peer.
+func (n *DexconNetwork) PeerNodeID(peer interface{}) (types.NodeID, bool) {
+	id, ok := peer.(string)
+	if !ok {
+		return types.NodeID{}, false
+	}
+	p := n.pm.peers.Peer(id)
+	if p == nil {
+		return types.NodeID{}, false
+	}
+	return types.NewNodeID(coreEcdsa.NewPublicKeyFromECDSA(p.Node().Pubkey())), true
+}
@@ -37,6 +37,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -66,6 +67,10 @@ const (
 
 	maxKnownDKGPrivateShares = 1024 // this related to DKG Size
 
+	maxKnownCoreBlocks = 1024 // Maximum core block hashes to keep in the known list
+
+	maxKnownHeartbeats = 1024 // Maximum heartbeat hashes to keep in the known list
+
 	// maxQueuedTxs is the maximum number of transaction lists to queue up before
 	// dropping broadcasts. This is a sensitive number as a transaction list might
 	// contain a single transaction, or thousands.
@@ -82,6 +87,7 @@ const (
 	maxQueuedAnns = 4
 
 	maxQueuedCoreBlocks           = 16
+	maxQueuedCoreBlockAnns        = 16
 	maxQueuedVotes                = 128
 	maxQueuedAgreements           = 16
 	maxQueuedDKGPrivateShare      = 16
@@ -89,6 +95,7 @@ const (
 	maxQueuedPullBlocks           = 128
 	maxQueuedPullVotes            = 128
 	maxQueuedPullRandomness       = 128
+	maxQueuedHeartbeats           = 16
 
 	handshakeTimeout = 5 * time.Second
 
@@ -99,9 +106,46 @@ const (
 // PeerInfo represents a short summary of the Ethereum sub-protocol metadata known
 // about a connected peer.
 type PeerInfo struct {
-	Version int    `json:"version"` // Ethereum protocol version negotiated
-	Number  uint64 `json:"number"`  // Number the peer's blockchain
-	Head    string `json:"head"`    // SHA3 hash of the peer's best owned block
+	Version      int    `json:"version"`      // Ethereum protocol version negotiated
+	Number       uint64 `json:"number"`       // Number the peer's blockchain
+	Head         string `json:"head"`         // SHA3 hash of the peer's best owned block
+	Capabilities string `json:"capabilities"` // Services advertised by the peer
+}
+
+// peerCapabilities is a bitset of services a peer advertises in handshake.
+type peerCapabilities uint64
+
+const (
+	// capArchive means the peer serves the complete chain history.
+	capArchive peerCapabilities = 1 << iota
+	// capLightServer means the peer serves light client proofs.
+	capLightServer
+	// capRelay means the peer relays consensus messages and serves pulls of
+	// them.
+	capRelay
+)
+
+// defaultPeerCapabilities are capabilities of a full node, which are assumed
+// for peers not advertising any.
+const defaultPeerCapabilities = capArchive | capRelay
+
+// Has checks if all capabilities in 'caps' are supported.
+func (c peerCapabilities) Has(caps peerCapabilities) bool {
+	return c&caps == caps
+}
+
+func (c peerCapabilities) String() string {
+	var names []string
+	if c.Has(capArchive) {
+		names = append(names, "archive")
+	}
+	if c.Has(capLightServer) {
+		names = append(names, "light-server")
+	}
+	if c.Has(capRelay) {
+		names = append(names, "relay")
+	}
+	return strings.Join(names, "|")
 }
 
 type setType uint32
@@ -134,18 +178,24 @@ type peer struct {
 
 	head   common.Hash
 	number uint64
+	caps   peerCapabilities // Capabilities advertised in handshake
 	lock   sync.RWMutex
 
+	votesPrunedBelow uint64 // Height below which the peer has pruned votes
+
 	lastKnownAgreementPositionLock sync.RWMutex
 	lastKnownAgreementPosition     coreTypes.Position // The position of latest agreement to be known by this peer
 	knownTxs                       mapset.Set         // Set of transaction hashes known to be known by this peer
 	knownBlocks                    mapset.Set         // Set of block hashes known to be known by this peer
 	knownAgreements                mapset.Set
 	knownDKGPrivateShares          mapset.Set
+	knownCoreBlocks                mapset.Set
+	knownHeartbeats                mapset.Set
 	queuedTxs                      chan []*types.Transaction // Queue of transactions to broadcast to the peer
 	queuedProps                    chan *types.Block         // Queue of blocks to broadcast to the peer
 	queuedAnns                     chan *types.Block         // Queue of blocks to announce to the peer
 	queuedCoreBlocks               chan []*coreTypes.Block
+	queuedCoreBlockAnns            chan []*coreBlockAnnouncement
 	queuedVotes                    chan []*coreTypes.Vote
 	queuedAgreements               chan *coreTypes.AgreementResult
 	queuedDKGPrivateShares         chan *dkgTypes.PrivateShare
@@ -153,6 +203,7 @@ type peer struct {
 	queuedPullBlocks               chan coreCommon.Hashes
 	queuedPullVotes                chan coreTypes.Position
 	queuedPullRandomness           chan coreCommon.Hashes
+	queuedHeartbeats               chan *coreTypes.Heartbeat
 	term                           chan struct{} // Termination channel to stop the broadcaster
 }
 
@@ -166,10 +217,13 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 		knownBlocks:                mapset.NewSet(),
 		knownAgreements:            mapset.NewSet(),
 		knownDKGPrivateShares:      mapset.NewSet(),
+		knownCoreBlocks:            mapset.NewSet(),
+		knownHeartbeats:            mapset.NewSet(),
 		queuedTxs:                  make(chan []*types.Transaction, maxQueuedTxs),
 		queuedProps:                make(chan *types.Block, maxQueuedProps),
 		queuedAnns:                 make(chan *types.Block, maxQueuedAnns),
 		queuedCoreBlocks:           make(chan []*coreTypes.Block, maxQueuedCoreBlocks),
+		queuedCoreBlockAnns:        make(chan []*coreBlockAnnouncement, maxQueuedCoreBlockAnns),
 		queuedVotes:                make(chan []*coreTypes.Vote, maxQueuedVotes),
 		queuedAgreements:           make(chan *coreTypes.AgreementResult, maxQueuedAgreements),
 		queuedDKGPrivateShares:     make(chan *dkgTypes.PrivateShare, maxQueuedDKGPrivateShare),
@@ -177,6 +231,7 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 		queuedPullBlocks:           make(chan coreCommon.Hashes, maxQueuedPullBlocks),
 		queuedPullVotes:            make(chan coreTypes.Position, maxQueuedPullVotes),
 		queuedPullRandomness:       make(chan coreCommon.Hashes, maxQueuedPullRandomness),
+		queuedHeartbeats:           make(chan *coreTypes.Heartbeat, maxQueuedHeartbeats),
 		term:                       make(chan struct{}),
 	}
 }
@@ -220,6 +275,11 @@ func (p *peer) broadcast() {
 				return
 			}
 			p.Log().Trace("Broadcast core blocks", "count", len(blocks))
+		case anns := <-p.queuedCoreBlockAnns:
+			if err := p.SendCoreBlockAnnouncements(anns); err != nil {
+				return
+			}
+			p.Log().Trace("Announced core blocks", "count", len(anns))
 		case votes := <-p.queuedVotes:
 			if err := p.SendVotes(votes); err != nil {
 				return
@@ -240,6 +300,11 @@ func (p *peer) broadcast() {
 				return
 			}
 			p.Log().Trace("Broadcast DKG partial signature")
+		case heartbeat := <-p.queuedHeartbeats:
+			if err := p.SendHeartbeat(heartbeat); err != nil {
+				return
+			}
+			p.Log().Trace("Broadcast heartbeat")
 		case hashes := <-p.queuedPullBlocks:
 			if err := p.SendPullBlocks(hashes); err != nil {
 				return
@@ -275,12 +340,21 @@ func (p *peer) Info() *PeerInfo {
 	hash, number := p.Head()
 
 	return &PeerInfo{
-		Version: p.version,
-		Number:  number,
-		Head:    hash.Hex(),
+		Version:      p.version,
+		Number:       number,
+		Head:         hash.Hex(),
+		Capabilities: p.Capabilities().String(),
 	}
 }
 
+// Capabilities retrieves services advertised by the peer.
+func (p *peer) Capabilities() peerCapabilities {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.caps
+}
+
 // Head retrieves a copy of the current head hash and number of the
 // peer.
 func (p *peer) Head() (hash common.Hash, number uint64) {
@@ -310,6 +384,15 @@ func (p *peer) MarkBlock(hash common.Hash) {
 	p.knownBlocks.Add(hash)
 }
 
+// MarkCoreBlock marks a core block as held by the peer, which could serve pulls
+// of it.
+func (p *peer) MarkCoreBlock(hash coreCommon.Hash) {
+	for p.knownCoreBlocks.Cardinality() >= maxKnownCoreBlocks {
+		p.knownCoreBlocks.Pop()
+	}
+	p.knownCoreBlocks.Add(hash)
+}
+
 // MarkTransaction marks a transaction as known for the peer, ensuring that it
 // will never be propagated to this particular peer.
 func (p *peer) MarkTransaction(hash common.Hash) {
@@ -330,6 +413,13 @@ func (p *peer) MarkAgreement(position coreTypes.Position) bool {
 	return false
 }
 
+func (p *peer) MarkHeartbeat(hash common.Hash) {
+	for p.knownHeartbeats.Cardinality() >= maxKnownHeartbeats {
+		p.knownHeartbeats.Pop()
+	}
+	p.knownHeartbeats.Add(hash)
+}
+
 func (p *peer) MarkDKGPrivateShares(hash common.Hash) {
 	for p.knownDKGPrivateShares.Cardinality() >= maxKnownDKGPrivateShares {
 		p.knownDKGPrivateShares.Pop()
@@ -416,6 +506,9 @@ func (p *peer) AsyncSendNewBlock(block *types.Block) {
 }
 
 func (p *peer) SendCoreBlocks(blocks []*coreTypes.Block) error {
+	for _, b := range blocks {
+		p.MarkCoreBlock(b.Hash)
+	}
 	return p.logSend(p2p.Send(p.rw, CoreBlockMsg, blocks), CoreBlockMsg)
 }
 
@@ -427,6 +520,18 @@ func (p *peer) AsyncSendCoreBlocks(blocks []*coreTypes.Block) {
 	}
 }
 
+func (p *peer) SendCoreBlockAnnouncements(anns []*coreBlockAnnouncement) error {
+	return p.logSend(p2p.Send(p.rw, CoreBlockAnnounceMsg, anns), CoreBlockAnnounceMsg)
+}
+
+func (p *peer) AsyncSendCoreBlockAnnouncements(anns []*coreBlockAnnouncement) {
+	select {
+	case p.queuedCoreBlockAnns <- anns:
+	default:
+		p.Log().Debug("Dropping core block announcement")
+	}
+}
+
 func (p *peer) SendVotes(votes []*coreTypes.Vote) error {
 	return p.logSend(p2p.Send(p.rw, VoteMsg, votes), VoteMsg)
 }
@@ -467,6 +572,20 @@ func (p *peer) AsyncSendDKGPrivateShare(privateShare *dkgTypes.PrivateShare) {
 	}
 }
 
+func (p *peer) SendHeartbeat(heartbeat *coreTypes.Heartbeat) error {
+	p.MarkHeartbeat(rlpHash(heartbeat))
+	return p.logSend(p2p.Send(p.rw, HeartbeatMsg, heartbeat), HeartbeatMsg)
+}
+
+func (p *peer) AsyncSendHeartbeat(heartbeat *coreTypes.Heartbeat) {
+	select {
+	case p.queuedHeartbeats <- heartbeat:
+		p.MarkHeartbeat(rlpHash(heartbeat))
+	default:
+		p.Log().Debug("Dropping heartbeat")
+	}
+}
+
 func (p *peer) SendDKGPartialSignature(psig *dkgTypes.PartialSignature) error {
 	return p.logSend(p2p.Send(p.rw, DKGPartialSignatureMsg, psig), DKGPartialSignatureMsg)
 }
@@ -495,6 +614,29 @@ func (p *peer) SendPullVotes(pos coreTypes.Position) error {
 	return p.logSend(p2p.Send(p.rw, PullVotesMsg, pos), PullVotesMsg)
 }
 
+func (p *peer) SendVotesPruned(pos coreTypes.Position, prunedBelow uint64) error {
+	return p.logSend(p2p.Send(p.rw, VotesPrunedMsg, &votesPruned{
+		Position:    pos,
+		PrunedBelow: prunedBelow,
+	}), VotesPrunedMsg)
+}
+
+// SetVotesPrunedBelow records the height below which the peer has pruned votes.
+func (p *peer) SetVotesPrunedBelow(height uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if height > p.votesPrunedBelow {
+		p.votesPrunedBelow = height
+	}
+}
+
+// VotesPrunedBelow returns the height below which the peer has pruned votes.
+func (p *peer) VotesPrunedBelow() uint64 {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.votesPrunedBelow
+}
+
 func (p *peer) AsyncSendPullVotes(pos coreTypes.Position) {
 	select {
 	case p.queuedPullVotes <- pos:
@@ -591,18 +733,29 @@ func (p *peer) RequestReceipts(hashes []common.Hash) error {
 
 // Handshake executes the eth protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis blocks.
-func (p *peer) Handshake(network uint64, number uint64, head common.Hash, genesis common.Hash) error {
+func (p *peer) Handshake(network uint64, number uint64, head common.Hash, genesis common.Hash, caps peerCapabilities) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
-	var status statusData // safe to read after two values have been received from errc
+	var status statusData65 // safe to read after two values have been received from errc
 
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &statusData{
+		if p.version < dex65 {
+			errc <- p2p.Send(p.rw, StatusMsg, &statusData{
+				ProtocolVersion: uint32(p.version),
+				NetworkId:       network,
+				Number:          number,
+				CurrentBlock:    head,
+				GenesisBlock:    genesis,
+			})
+			return
+		}
+		errc <- p2p.Send(p.rw, StatusMsg, &statusData65{
 			ProtocolVersion: uint32(p.version),
 			NetworkId:       network,
 			Number:          number,
 			CurrentBlock:    head,
 			GenesisBlock:    genesis,
+			Capabilities:    caps,
 		})
 	}()
 	go func() {
@@ -620,11 +773,13 @@ func (p *peer) Handshake(network uint64, number uint64, head common.Hash, genesi
 			return p2p.DiscReadTimeout
 		}
 	}
-	p.number, p.head = status.Number, status.CurrentBlock
+	p.number, p.head, p.caps = status.Number, status.CurrentBlock, status.Capabilities
 	return nil
 }
 
-func (p *peer) readStatus(network uint64, status *statusData, genesis common.Hash) (err error) {
+// readStatus reads the status message of the negotiated version, peers before
+// dex65 don't advertise capabilities and are regarded as full nodes.
+func (p *peer) readStatus(network uint64, status *statusData65, genesis common.Hash) (err error) {
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
@@ -636,7 +791,20 @@ func (p *peer) readStatus(network uint64, status *statusData, genesis common.Has
 		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, ProtocolMaxMsgSize)
 	}
 	// Decode the handshake and make sure everything matches
-	if err := msg.Decode(&status); err != nil {
+	if p.version < dex65 {
+		var legacy statusData
+		if err := msg.Decode(&legacy); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		*status = statusData65{
+			ProtocolVersion: legacy.ProtocolVersion,
+			NetworkId:       legacy.NetworkId,
+			Number:          legacy.Number,
+			CurrentBlock:    legacy.CurrentBlock,
+			GenesisBlock:    legacy.GenesisBlock,
+			Capabilities:    defaultPeerCapabilities,
+		}
+	} else if err := msg.Decode(status); err != nil {
 		return errResp(ErrDecode, "msg %v: %v", msg, err)
 	}
 	if status.GenesisBlock != genesis {
@@ -820,8 +988,56 @@ func (ps *peerSet) PeersWithoutDKGPrivateShares(hash common.Hash) []*peer {
 	return list
 }
 
+// PeersWithoutHeartbeat retrieves a list of peers that do not have a given
+// heartbeat in their set of known hashes. Peers before dex65 don't support
+// heartbeats, they are excluded.
+func (ps *peerSet) PeersWithoutHeartbeat(hash common.Hash) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if p.version >= dex65 && !p.knownHeartbeats.Contains(hash) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// PeersWithCapabilities retrieves a list of peers advertising all capabilities
+// in 'caps'.
+func (ps *peerSet) PeersWithCapabilities(caps peerCapabilities) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if p.Capabilities().Has(caps) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// PeersWithCoreBlock retrieves a list of peers known to hold a given core block.
+func (ps *peerSet) PeersWithCoreBlock(hash coreCommon.Hash) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if p.knownCoreBlocks.Contains(hash) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
 // BestPeer retrieves the known peer with the currently highest total difficulty.
 func (ps *peerSet) BestPeer() *peer {
+	return ps.BestPeerWithCapabilities(0)
+}
+
+// BestPeerWithCapabilities retrieves the known peer with the currently highest
+// total difficulty among peers advertising all capabilities in 'caps'.
+func (ps *peerSet) BestPeerWithCapabilities(caps peerCapabilities) *peer {
 	ps.lock.RLock()
 	defer ps.lock.RUnlock()
 
@@ -830,6 +1046,9 @@ func (ps *peerSet) BestPeer() *peer {
 		bestNumber uint64
 	)
 	for _, p := range ps.peers {
+		if !p.Capabilities().Has(caps) {
+			continue
+		}
 		if _, number := p.Head(); bestPeer == nil || number > bestNumber {
 			bestPeer, bestNumber = p, number
 		}
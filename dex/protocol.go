@@ -38,6 +38,9 @@ import (
 	"fmt"
 	"io"
 
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+
 	"github.com/dexon-foundation/dexon/common"
 	"github.com/dexon-foundation/dexon/core"
 	"github.com/dexon-foundation/dexon/core/types"
@@ -50,16 +53,17 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	dex64 = 64
+	dex65 = 65
 )
 
 // ProtocolName is the official short name of the protocol used during capability negotiation.
 var ProtocolName = "dex"
 
 // ProtocolVersions are the supported versions of the eth protocol (first is primary).
-var ProtocolVersions = []uint{dex64}
+var ProtocolVersions = []uint{dex65, dex64}
 
 // ProtocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{43}
+var ProtocolLengths = []uint64{44, 43}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -92,6 +96,11 @@ const (
 
 	GetGovStateMsg = 0x29
 	GovStateMsg    = 0x2a
+
+	// Protocol messages belonging to dex/65
+	CoreBlockAnnounceMsg = 0x27
+	VotesPrunedMsg       = 0x28
+	HeartbeatMsg         = 0x2b
 )
 
 type errCode int
@@ -174,7 +183,7 @@ type p2pServer interface {
 	RemoveDirectPeer(*enode.Node)
 }
 
-// statusData is the network packet for the status message.
+// statusData is the network packet for the status message of dex64.
 type statusData struct {
 	ProtocolVersion uint32
 	NetworkId       uint64
@@ -183,6 +192,45 @@ type statusData struct {
 	GenesisBlock    common.Hash
 }
 
+// statusData65 is the network packet for the status message since dex65,
+// which advertises capabilities of the peer.
+type statusData65 struct {
+	ProtocolVersion uint32
+	NetworkId       uint64
+	Number          uint64
+	CurrentBlock    common.Hash
+	GenesisBlock    common.Hash
+	Capabilities    peerCapabilities
+}
+
+// coreBlockAnnouncement is the network packet announcing a core block without
+// its payload, which is pulled on demand.
+type coreBlockAnnouncement struct {
+	Hash        coreCommon.Hash
+	ProposerID  coreTypes.NodeID
+	ParentHash  coreCommon.Hash
+	Position    coreTypes.Position
+	PayloadHash coreCommon.Hash
+}
+
+func newCoreBlockAnnouncement(b *coreTypes.Block) *coreBlockAnnouncement {
+	return &coreBlockAnnouncement{
+		Hash:        b.Hash,
+		ProposerID:  b.ProposerID,
+		ParentHash:  b.ParentHash,
+		Position:    b.Position,
+		PayloadHash: b.PayloadHash,
+	}
+}
+
+// votesPruned is the network packet replying a vote pull for a position of
+// which votes are pruned.
+type votesPruned struct {
+	Position coreTypes.Position
+	// PrunedBelow is the height below which votes are pruned.
+	PrunedBelow uint64
+}
+
 // newBlockHashesData is the network packet for the block announcements.
 type newBlockHashesData []struct {
 	Hash   common.Hash // Hash of one particular block being announced
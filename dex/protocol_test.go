@@ -20,6 +20,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -27,6 +28,7 @@ import (
 	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
 	coreCrypto "github.com/dexon-foundation/dexon-consensus/core/crypto"
 	"github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
 	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
 	dkgTypes "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
 
@@ -101,6 +103,112 @@ func testStatusMsgErrors(t *testing.T, protocol int) {
 	}
 }
 
+// This test checks that capabilities advertised in dex65 handshakes are
+// recorded, and dex64 peers not advertising any are regarded as full nodes.
+func TestStatusMsgCapabilities(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	var (
+		genesis = pm.blockchain.Genesis()
+		head    = pm.blockchain.CurrentHeader()
+		number  = head.Number.Uint64()
+	)
+	defer pm.Stop()
+
+	tests := []struct {
+		version int
+		status  interface{}
+		want    peerCapabilities
+	}{
+		{
+			version: dex64,
+			status:  &statusData{dex64, DefaultConfig.NetworkId, number, head.Hash(), genesis.Hash()},
+			want:    defaultPeerCapabilities,
+		},
+		{
+			version: dex65,
+			status:  &statusData65{dex65, DefaultConfig.NetworkId, number, head.Hash(), genesis.Hash(), capLightServer},
+			want:    capLightServer,
+		},
+	}
+	for i, test := range tests {
+		p, _ := newTestPeer(fmt.Sprintf("peer %d", i), test.version, pm, false)
+		if err := p2p.ExpectMsg(p.app, StatusMsg, nil); err != nil {
+			t.Fatalf("test %d: status recv: %v", i, err)
+		}
+		if err := p2p.Send(p.app, StatusMsg, test.status); err != nil {
+			t.Fatalf("test %d: status send: %v", i, err)
+		}
+		var registered *peer
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+			if registered = pm.peers.Peer(p.id); registered != nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if registered == nil {
+			t.Fatalf("test %d: peer not registered within 2 seconds", i)
+		}
+		if caps := registered.Capabilities(); caps != test.want {
+			t.Errorf("test %d: capabilities mismatch: got %v, want %v", i, caps, test.want)
+		}
+		found := false
+		for _, capable := range pm.peers.PeersWithCapabilities(test.want) {
+			found = found || capable == registered
+		}
+		if !found {
+			t.Errorf("test %d: peer not selected by its capabilities", i)
+		}
+		p.close()
+	}
+}
+
+// This test checks that the status message of each version is decoded
+// strictly, dex64 peers never receive or send capabilities.
+func TestStatusMsgVersions(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	var (
+		genesis = pm.blockchain.Genesis()
+		head    = pm.blockchain.CurrentHeader()
+		number  = head.Number.Uint64()
+	)
+	defer pm.Stop()
+
+	for i, version := range []int{dex64, dex65} {
+		p, _ := newTestPeer(fmt.Sprintf("peer %d", i), version, pm, false)
+		msg, err := p.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("test %d: status recv: %v", i, err)
+		}
+		if version == dex64 {
+			var status statusData
+			if err := msg.Decode(&status); err != nil {
+				t.Errorf("test %d: dex64 status not decoded strictly: %v", i, err)
+			}
+		} else {
+			var status statusData65
+			if err := msg.Decode(&status); err != nil {
+				t.Errorf("test %d: dex65 status not decoded: %v", i, err)
+			} else if status.Capabilities != defaultPeerCapabilities {
+				t.Errorf("test %d: capabilities mismatch: got %v, want %v", i, status.Capabilities, defaultPeerCapabilities)
+			}
+		}
+		p.close()
+	}
+
+	// A dex65 status without capabilities is rejected.
+	p, errc := newTestPeer("peer", dex65, pm, false)
+	go p2p.Send(p.app, StatusMsg, &statusData{dex65, DefaultConfig.NetworkId, number, head.Hash(), genesis.Hash()})
+	select {
+	case err := <-errc:
+		if err == nil || !strings.Contains(err.Error(), errorToString[ErrDecode]) {
+			t.Errorf("wrong error: got %v, want decode error", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("protocol did not shut down within 2 seconds")
+	}
+	p.close()
+}
+
 // This test checks that received transactions are added to the local pool.
 func TestRecvTransactions62(t *testing.T) { testRecvTransactions(t, 62) }
 func TestRecvTransactions63(t *testing.T) { testRecvTransactions(t, 63) }
@@ -280,6 +388,69 @@ func TestRecvCoreBlocks(t *testing.T) {
 	}
 }
 
+// This test checks that core block announcements are only accepted from dex65
+// peers, and unknown blocks are pulled from the announcer.
+func TestRecvCoreBlockAnnouncements(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+	defer pm.Stop()
+
+	ann := &coreBlockAnnouncement{
+		Hash:     coreCommon.Hash{2, 2, 2, 2, 2},
+		Position: coreTypes.Position{Round: 12, Height: 13},
+	}
+	known := &coreTypes.Block{
+		Hash:     coreCommon.Hash{3, 3, 3, 3, 3},
+		Position: coreTypes.Position{Round: 12, Height: 13},
+	}
+	pm.cache.addBlock(known)
+
+	p, errc := newTestPeer("peer", dex65, pm, true)
+	anns := []*coreBlockAnnouncement{ann, newCoreBlockAnnouncement(known)}
+	if err := p2p.Send(p.app, CoreBlockAnnounceMsg, anns); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+	msg, err := p.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if msg.Code != PullBlocksMsg {
+		t.Fatalf("got code %d, want %d", msg.Code, PullBlocksMsg)
+	}
+	var hashes coreCommon.Hashes
+	if err := msg.Decode(&hashes); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if !reflect.DeepEqual(hashes, coreCommon.Hashes{ann.Hash}) {
+		t.Errorf("pulled hashes mismatch: got %v, want %v",
+			hashes, coreCommon.Hashes{ann.Hash})
+	}
+	for _, ann := range anns {
+		if !p.knownCoreBlocks.Contains(ann.Hash) {
+			t.Errorf("announced block not marked known")
+		}
+	}
+	select {
+	case err := <-errc:
+		t.Fatalf("protocol returned error: %v", err)
+	default:
+	}
+	p.close()
+
+	p, errc = newTestPeer("peer", dex64, pm, true)
+	defer p.close()
+	go p2p.Send(p.app, CoreBlockAnnounceMsg, []*coreBlockAnnouncement{ann})
+	want := errResp(ErrInvalidMsgCode, "%v", CoreBlockAnnounceMsg)
+	select {
+	case err := <-errc:
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("wrong error: got %v, want %v", err, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("protocol did not shut down within 2 seconds")
+	}
+}
+
 func TestSendCoreBlocks(t *testing.T) {
 	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
 	pm.SetReceiveCoreMessage(true)
@@ -381,6 +552,84 @@ func TestSendCoreBlocks(t *testing.T) {
 	wg.Wait()
 }
 
+func TestSendCoreBlocksPushPull(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+	pm.blockPropagation = PushPullPropagation
+	defer pm.Stop()
+
+	block := coreTypes.Block{
+		ProposerID:  coreTypes.NodeID{coreCommon.Hash{1, 2, 3}},
+		ParentHash:  coreCommon.Hash{1, 1, 1, 1, 1},
+		Hash:        coreCommon.Hash{2, 2, 2, 2, 2},
+		Position:    coreTypes.Position{Round: 12, Height: 13},
+		Timestamp:   time.Now().UTC(),
+		Payload:     []byte{3, 3, 3, 3, 3},
+		PayloadHash: coreCommon.Hash{3, 3, 3, 3, 3},
+	}
+
+	var (
+		wg    sync.WaitGroup
+		lock  sync.Mutex
+		codes = make(map[uint64]int)
+	)
+	checkMsg := func(p *testPeer) {
+		defer wg.Done()
+		defer p.close()
+		msg, err := p.app.ReadMsg()
+		if err != nil {
+			t.Errorf("%v: read error: %v", p.Peer, err)
+			return
+		}
+		switch msg.Code {
+		case CoreBlockMsg:
+			var bs []*coreTypes.Block
+			if err := msg.Decode(&bs); err != nil {
+				t.Errorf("%v: %v", p.Peer, err)
+			} else if len(bs) != 1 || bs[0].Hash != block.Hash ||
+				!reflect.DeepEqual(bs[0].Payload, block.Payload) {
+				t.Errorf("block mismatch")
+			}
+		case CoreBlockAnnounceMsg:
+			if p.version < dex65 {
+				t.Errorf("%v: announcement sent to dex%d peer", p.Peer, p.version)
+			}
+			var anns []*coreBlockAnnouncement
+			if err := msg.Decode(&anns); err != nil {
+				t.Errorf("%v: %v", p.Peer, err)
+			} else if !reflect.DeepEqual(anns, []*coreBlockAnnouncement{
+				newCoreBlockAnnouncement(&block)}) {
+				t.Errorf("announcement mismatch")
+			}
+		default:
+			t.Errorf("%v: unexpected code %d", p.Peer, msg.Code)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		codes[msg.Code]++
+	}
+
+	label := peerLabel{set: notaryset, round: 12}
+	pm.peers.label2Nodes = map[peerLabel]map[string]*enode.Node{
+		label: make(map[string]*enode.Node),
+	}
+	// The dex64 peer always receives the full block.
+	versions := []int{dex65, dex65, dex65, dex65, dex64}
+	for i, version := range versions {
+		p, _ := newTestPeer(fmt.Sprintf("peer #%d", i), version, pm, true)
+		pm.peers.label2Nodes[label][p.ID().String()] = p.Node()
+		pm.peers.addDirectPeer(p.ID().String(), label)
+		wg.Add(1)
+		go checkMsg(p)
+	}
+	waitForRegister(pm, len(versions))
+	pm.BroadcastCoreBlock(&block)
+	wg.Wait()
+	if codes[CoreBlockMsg] != 3 || codes[CoreBlockAnnounceMsg] != 2 {
+		t.Errorf("propagation mismatch: got %v", codes)
+	}
+}
+
 func TestRecvVotes(t *testing.T) {
 	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
 	pm.SetReceiveCoreMessage(true)
@@ -416,8 +665,8 @@ func TestRecvVotes(t *testing.T) {
 
 	select {
 	case msg := <-ch:
-		rvote := msg.Payload.(*coreTypes.Vote)
-		if !reflect.DeepEqual(rvote, &vote) {
+		rvotes := msg.Payload.([]*coreTypes.Vote)
+		if !reflect.DeepEqual(rvotes, []*coreTypes.Vote{&vote}) {
 			t.Errorf("vote mismatch")
 		}
 	case <-time.After(1 * time.Second):
@@ -529,6 +778,95 @@ func (p *mockPublicKey) Bytes() []byte {
 	return crypto.FromECDSAPub((*ecdsa.PublicKey)(p))
 }
 
+// This test checks that pulling pruned votes is replied by VotesPrunedMsg to
+// dex65 peers, and by an empty vote set to older peers.
+func TestPullPrunedVotes(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+	defer pm.Stop()
+
+	pm.cache.setRetention(3)
+	for height := uint64(1); height <= 5; height++ {
+		pm.cache.addFinalizedBlock(&coreTypes.Block{
+			Hash:       coreCommon.NewRandomHash(),
+			Position:   coreTypes.Position{Height: height},
+			Randomness: []byte{0x1},
+		})
+	}
+	pos := coreTypes.Position{Height: 1}
+
+	for _, version := range []int{dex64, dex65} {
+		p, _ := newTestPeer("peer", version, pm, true)
+		if err := p2p.Send(p.app, PullVotesMsg, pos); err != nil {
+			t.Fatalf("dex%d: send error: %v", version, err)
+		}
+		msg, err := p.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("dex%d: read error: %v", version, err)
+		}
+		switch version {
+		case dex64:
+			var votes []*coreTypes.Vote
+			if msg.Code != VoteMsg {
+				t.Fatalf("dex%d: got code %d, want %d", version, msg.Code, VoteMsg)
+			} else if err := msg.Decode(&votes); err != nil {
+				t.Fatalf("dex%d: %v", version, err)
+			} else if len(votes) != 0 {
+				t.Errorf("dex%d: got %d votes, want none", version, len(votes))
+			}
+		case dex65:
+			var pruned votesPruned
+			if msg.Code != VotesPrunedMsg {
+				t.Fatalf("dex%d: got code %d, want %d", version, msg.Code, VotesPrunedMsg)
+			} else if err := msg.Decode(&pruned); err != nil {
+				t.Fatalf("dex%d: %v", version, err)
+			}
+			want := votesPruned{Position: pos, PrunedBelow: 3}
+			if !reflect.DeepEqual(pruned, want) {
+				t.Errorf("dex%d: got %+v, want %+v", version, pruned, want)
+			}
+		}
+		p.close()
+	}
+}
+
+// This test checks that VotesPrunedMsg is only accepted from dex65 peers.
+func TestRecvVotesPruned(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	pruned := &votesPruned{
+		Position:    coreTypes.Position{Height: 1},
+		PrunedBelow: 3,
+	}
+
+	p, _ := newTestPeer("peer", dex65, pm, true)
+	if err := p2p.Send(p.app, VotesPrunedMsg, pruned); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+	// The next message is handled only after VotesPrunedMsg.
+	if err := p2p.Send(p.app, VotesPrunedMsg, pruned); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+	if below := p.VotesPrunedBelow(); below != 3 {
+		t.Errorf("got votes pruned below %d, want 3", below)
+	}
+	p.close()
+
+	p, errc := newTestPeer("peer", dex64, pm, true)
+	defer p.close()
+	go p2p.Send(p.app, VotesPrunedMsg, pruned)
+	want := errResp(ErrInvalidMsgCode, "%v", VotesPrunedMsg)
+	select {
+	case err := <-errc:
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("wrong error: got %v, want %v", err, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("protocol did not shut down within 2 seconds")
+	}
+}
+
 func TestRecvDKGPrivateShare(t *testing.T) {
 	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
 	pm.SetReceiveCoreMessage(true)
@@ -616,6 +954,104 @@ func TestSendDKGPrivateShare(t *testing.T) {
 	}
 }
 
+func TestRecvHeartbeat(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+
+	p1, _ := newTestPeer("peer1", dex65, pm, true)
+	p2, _ := newTestPeer("peer2", dex65, pm, true)
+	defer pm.Stop()
+	defer p1.close()
+	defer p2.close()
+
+	heartbeat := coreTypes.Heartbeat{
+		ProposerID: coreTypes.NodeID{coreCommon.Hash{1, 2, 3}},
+		Round:      10,
+		Height:     13,
+		Version:    "1.0.0",
+		Timestamp:  time.Unix(1500000000, 123).UTC(),
+		Signature: coreCrypto.Signature{
+			Type:      "123",
+			Signature: []byte("sig"),
+		},
+	}
+
+	if err := p2p.Send(p1.app, HeartbeatMsg, &heartbeat); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+
+	select {
+	case msg := <-pm.ReceiveChan():
+		rheartbeat := msg.Payload.(*coreTypes.Heartbeat)
+		if !reflect.DeepEqual(rheartbeat, &heartbeat) {
+			t.Errorf("heartbeat mismatch")
+		}
+	case <-time.After(1 * time.Second):
+		t.Errorf("no heartbeat received within 1 seconds")
+	}
+
+	// The heartbeat is not relayed until consensus core verifies it.
+	if p2.knownHeartbeats.Contains(rlpHash(&heartbeat)) {
+		t.Errorf("unverified heartbeat relayed")
+	}
+	if !p1.knownHeartbeats.Contains(rlpHash(&heartbeat)) {
+		t.Errorf("heartbeat not marked known for the sender")
+	}
+}
+
+// This test checks that heartbeats are only exchanged with dex65 peers.
+func TestSendHeartbeat(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+	defer pm.Stop()
+
+	p65, _ := newTestPeer("peer65", dex65, pm, true)
+	p64, errc := newTestPeer("peer64", dex64, pm, true)
+	defer p65.close()
+	defer p64.close()
+
+	heartbeat := coreTypes.Heartbeat{
+		ProposerID: coreTypes.NodeID{coreCommon.Hash{1, 2, 3}},
+		Round:      10,
+		Height:     13,
+		Version:    "1.0.0",
+		Timestamp:  time.Unix(1500000000, 123).UTC(),
+		Signature: coreCrypto.Signature{
+			Type:      "123",
+			Signature: []byte("sig"),
+		},
+	}
+	pm.BroadcastHeartbeat(&heartbeat)
+
+	msg, err := p65.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("%v: read error: %v", p65.Peer, err)
+	} else if msg.Code != HeartbeatMsg {
+		t.Fatalf("%v: got code %d, want %d", p65.Peer, msg.Code, HeartbeatMsg)
+	}
+	var sent coreTypes.Heartbeat
+	if err := msg.Decode(&sent); err != nil {
+		t.Fatalf("%v: %v", p65.Peer, err)
+	}
+	if !reflect.DeepEqual(sent, heartbeat) {
+		t.Errorf("sent heartbeat mismatch")
+	}
+	if p64.knownHeartbeats.Contains(rlpHash(&heartbeat)) {
+		t.Errorf("heartbeat sent to dex64 peer")
+	}
+
+	go p2p.Send(p64.app, HeartbeatMsg, &heartbeat)
+	want := errResp(ErrInvalidMsgCode, "%v", HeartbeatMsg)
+	select {
+	case err := <-errc:
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("wrong error: got %v, want %v", err, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("protocol did not shut down within 2 seconds")
+	}
+}
+
 func TestRecvAgreement(t *testing.T) {
 	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
 	pm.SetReceiveCoreMessage(true)
@@ -719,6 +1155,71 @@ func TestSendAgreement(t *testing.T) {
 	}
 }
 
+// This test checks that pulls of cached blocks are served without being rate
+// limited.
+func TestPullCachedBlocks(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+
+	p, _ := newTestPeer("peer", dex65, pm, true)
+	defer pm.Stop()
+	defer p.close()
+
+	block := &coreTypes.Block{
+		Hash:     coreCommon.Hash{2, 2, 2, 2, 2},
+		Position: coreTypes.Position{Round: 12, Height: 13},
+	}
+	pm.cache.addBlock(block)
+
+	for i := 0; i < 2; i++ {
+		err := p2p.Send(p.app, PullBlocksMsg, coreCommon.Hashes{block.Hash})
+		if err != nil {
+			t.Fatalf("send error: %v", err)
+		}
+		msg, err := p.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if msg.Code != CoreBlockMsg {
+			t.Fatalf("got code %d, want %d", msg.Code, CoreBlockMsg)
+		}
+		var blocks []*coreTypes.Block
+		if err := msg.Decode(&blocks); err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if len(blocks) != 1 || blocks[0].Hash != block.Hash {
+			t.Errorf("pulled blocks mismatch: %v", blocks)
+		}
+	}
+}
+
+func TestPeerNodeID(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	pm.SetReceiveCoreMessage(true)
+
+	p, _ := newTestPeer("peer", dex64, pm, true)
+	defer pm.Stop()
+	defer p.close()
+
+	waitForRegister(pm, 1)
+	network := NewDexconNetwork(pm)
+	nID, ok := network.PeerNodeID(p.ID().String())
+	if !ok {
+		t.Fatalf("node ID of a connected peer not found")
+	}
+	want := coreTypes.NewNodeID(
+		coreEcdsa.NewPublicKeyFromECDSA(p.Node().Pubkey()))
+	if nID != want {
+		t.Errorf("node ID mismatch: have %v, want %v", nID, want)
+	}
+	if _, ok := network.PeerNodeID(enode.ID{}.String()); ok {
+		t.Errorf("node ID of an unknown peer found")
+	}
+	if _, ok := network.PeerNodeID(p.ID()); ok {
+		t.Errorf("node ID of a malformed peer found")
+	}
+}
+
 func waitForRegister(pm *ProtocolManager, num int) {
 	for {
 		if pm.peers.Len() >= num {
@@ -0,0 +1,213 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	coreCrypto "github.com/dexon-foundation/dexon-consensus/core/crypto"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	"github.com/dexon-foundation/dexon-consensus/core/crypto/remote"
+	coreDb "github.com/dexon-foundation/dexon-consensus/core/db"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+	dkgTypes "github.com/dexon-foundation/dexon-consensus/core/types/dkg"
+	coreUtils "github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// serveTestRemoteSigner serves a new key by a remote signing server, and
+// returns the key and the address of the server.
+func serveTestRemoteSigner(t *testing.T) (
+	coreCrypto.PrivateKey, string, func()) {
+	prvKey, err := coreEcdsa.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("new private key error: %v", err)
+	}
+	store, err := coreDb.NewMemBackedDB()
+	if err != nil {
+		t.Fatalf("new db error: %v", err)
+	}
+	srv, err := remote.NewServer(prvKey, store)
+	if err != nil {
+		t.Fatalf("new remote signing server error: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %v", err)
+	}
+	g := grpc.NewServer()
+	srv.Register(g)
+	go g.Serve(l)
+	return prvKey, l.Addr().String(), g.Stop
+}
+
+// newTestRemoteSigner returns a new key served remotely, and a client dialed
+// to the server.
+func newTestRemoteSigner(t *testing.T) (
+	coreCrypto.PrivateKey, *remote.Client, func()) {
+	prvKey, addr, stop := serveTestRemoteSigner(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := remote.Dial(ctx, addr, time.Second, grpc.WithInsecure())
+	if err != nil {
+		stop()
+		t.Fatalf("dial remote signing server error: %v", err)
+	}
+	return prvKey, c, func() {
+		c.Close()
+		stop()
+	}
+}
+
+func TestRemoteSignerSignMessages(t *testing.T) {
+	prvKey, c, stop := newTestRemoteSigner(t)
+	defer stop()
+	if !bytes.Equal(c.PublicKey().Bytes(), prvKey.PublicKey().Bytes()) {
+		t.Fatalf("public key mismatch")
+	}
+	signer := coreUtils.NewSigner(c)
+
+	b := &coreTypes.Block{
+		ParentHash: coreCommon.NewRandomHash(),
+		Position:   coreTypes.Position{Round: 1, Height: 10},
+		Timestamp:  time.Now().UTC(),
+		Payload:    []byte{0x1, 0x2, 0x3},
+	}
+	if err := signer.SignBlock(b); err != nil {
+		t.Fatalf("sign block error: %v", err)
+	}
+	if err := coreUtils.VerifyBlockSignature(b); err != nil {
+		t.Fatalf("verify block signature error: %v", err)
+	}
+
+	h := &coreTypes.Heartbeat{Round: 1, Height: 10}
+	if err := signer.SignHeartbeat(h); err != nil {
+		t.Fatalf("sign heartbeat error: %v", err)
+	}
+	if ok, err := coreUtils.VerifyHeartbeatSignature(h); err != nil || !ok {
+		t.Fatalf("verify heartbeat signature failed: %v %v", ok, err)
+	}
+
+	mpk := &dkgTypes.MasterPublicKey{Round: 1}
+	if err := signer.SignDKGMasterPublicKey(mpk); err != nil {
+		t.Fatalf("sign master public key error: %v", err)
+	}
+	if ok, err := coreUtils.VerifyDKGMasterPublicKeySignature(
+		mpk); err != nil || !ok {
+		t.Fatalf("verify master public key signature failed: %v %v",
+			ok, err)
+	}
+
+	ready := &dkgTypes.MPKReady{Round: 1}
+	if err := signer.SignDKGMPKReady(ready); err != nil {
+		t.Fatalf("sign mpk ready error: %v", err)
+	}
+	if ok, err := coreUtils.VerifyDKGMPKReadySignature(
+		ready); err != nil || !ok {
+		t.Fatalf("verify mpk ready signature failed: %v %v", ok, err)
+	}
+
+	final := &dkgTypes.Finalize{Round: 1}
+	if err := signer.SignDKGFinalize(final); err != nil {
+		t.Fatalf("sign finalize error: %v", err)
+	}
+	if ok, err := coreUtils.VerifyDKGFinalizeSignature(
+		final); err != nil || !ok {
+		t.Fatalf("verify finalize signature failed: %v %v", ok, err)
+	}
+
+	// Votes are still guarded against double signing.
+	v := coreTypes.NewVote(coreTypes.VoteInit, coreCommon.NewRandomHash(), 0)
+	v.Position = b.Position
+	if err := signer.SignVote(v); err != nil {
+		t.Fatalf("sign vote error: %v", err)
+	}
+	if ok, err := coreUtils.VerifyVoteSignature(v); err != nil || !ok {
+		t.Fatalf("verify vote signature failed: %v %v", ok, err)
+	}
+	conflict := coreTypes.NewVote(
+		coreTypes.VoteInit, coreCommon.NewRandomHash(), 0)
+	conflict.Position = b.Position
+	if err := signer.SignVote(conflict); err != coreUtils.ErrDoubleSign {
+		t.Fatalf("unexpected error of double signing: %v", err)
+	}
+}
+
+func TestRemoteSignerRefuseRawHashes(t *testing.T) {
+	_, c, stop := newTestRemoteSigner(t)
+	defer stop()
+
+	// The hash of a conflicting vote can't be signed directly.
+	v := coreTypes.NewVote(coreTypes.VoteCom, coreCommon.NewRandomHash(), 0)
+	v.ProposerID = coreTypes.NewNodeID(c.PublicKey())
+	if _, err := c.Sign(coreUtils.HashVote(v)); err !=
+		remote.ErrRawHashNotSigned {
+		t.Fatalf("unexpected error of signing raw hash: %v", err)
+	}
+	if _, err := c.SignMessage(v); err != coreUtils.ErrUnknownSignedMessage {
+		t.Fatalf("unexpected error of signing vote as message: %v", err)
+	}
+
+	// Messages proposed by others are refused.
+	other, err := coreEcdsa.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("new private key error: %v", err)
+	}
+	mpk := &dkgTypes.MasterPublicKey{
+		ProposerID: coreTypes.NewNodeID(other.PublicKey()),
+		Round:      1,
+	}
+	if _, err := c.SignMessage(mpk); err != remote.ErrProposerMismatch {
+		t.Fatalf("unexpected error of signing others' message: %v", err)
+	}
+}
+
+// rawSignRequest is the request of the removed method signing raw hashes.
+type rawSignRequest struct {
+	Hash coreCommon.Hash
+}
+
+func TestRemoteSignerRawSignMethodRemoved(t *testing.T) {
+	_, addr, stop := serveTestRemoteSigner(t)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype("rlp")))
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	var reply struct {
+		Signature coreCrypto.Signature
+	}
+	err = conn.Invoke(ctx, "/dexon.consensus.RemoteSigner/Sign",
+		&rawSignRequest{Hash: coreCommon.NewRandomHash()}, &reply)
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("signing raw hash should be unimplemented: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"bytes"
+	"testing"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	coreDKG "github.com/dexon-foundation/dexon-consensus/core/crypto/dkg"
+)
+
+func newTestReshareIDs(from, count int) coreDKG.IDs {
+	ids := make(coreDKG.IDs, count)
+	for i := range ids {
+		ids[i] = coreDKG.NewID([]byte{byte(from + i)})
+	}
+	return ids
+}
+
+func TestReshareGroupSecret(t *testing.T) {
+	const oldThreshold, newThreshold = 3, 2
+	oldIDs := newTestReshareIDs(1, 4)
+	newIDs := newTestReshareIDs(11, 3)
+	oldPrvs, oldPubs := coreDKG.NewPrivateKeyShares(oldThreshold)
+	oldPrvs.SetParticipants(oldIDs)
+	gpk := oldPubs.GroupPublicKey()
+
+	// Each member of the old committee deals its share to the new one.
+	received := make([]*coreDKG.PrivateKeyShares, len(newIDs))
+	for j := range received {
+		received[j] = coreDKG.NewEmptyPrivateKeyShares()
+	}
+	var dealerShares []*coreDKG.PublicKeyShares
+	for _, dealerID := range oldIDs {
+		share, exist := oldPrvs.Share(dealerID)
+		if !exist {
+			t.Fatalf("share of dealer not found")
+		}
+		prvs, pubs, err := coreDKG.NewResharePrivateKeyShares(
+			share, newThreshold)
+		if err != nil {
+			t.Fatalf("new reshare private key shares error: %v", err)
+		}
+		if pubs.Threshold() != newThreshold {
+			t.Fatalf("unexpected threshold: %d", pubs.Threshold())
+		}
+		oldPub, err := oldPubs.Share(dealerID)
+		if err != nil {
+			t.Fatalf("public key share of dealer error: %v", err)
+		}
+		if !coreDKG.VerifyReshareCommitment(oldPub, pubs) {
+			t.Fatalf("reshare commitment mismatch")
+		}
+		prvs.SetParticipants(newIDs)
+		for j, id := range newIDs {
+			sub, exist := prvs.Share(id)
+			if !exist {
+				t.Fatalf("sub-share not found")
+			}
+			if ok, err := pubs.VerifyPrvShare(id, sub); err != nil || !ok {
+				t.Fatalf("sub-share mismatch: %v", err)
+			}
+			if err := received[j].AddShare(dealerID, sub); err != nil {
+				t.Fatalf("add share error: %v", err)
+			}
+		}
+		dealerShares = append(dealerShares, pubs)
+	}
+	// A dealer resharing anything but its own share is detected.
+	if coreDKG.VerifyReshareCommitment(gpk, dealerShares[0]) {
+		t.Fatalf("commitment of another share is verified")
+	}
+
+	// The group public key is kept by the new committee.
+	pubs, err := coreDKG.RecoverResharedPublicKeyShares(
+		oldIDs, dealerShares, oldThreshold)
+	if err != nil {
+		t.Fatalf("recover reshared public key shares error: %v", err)
+	}
+	if !bytes.Equal(pubs.GroupPublicKey().Bytes(), gpk.Bytes()) {
+		t.Fatalf("group public key changed")
+	}
+	// Other shares differ by dealers, but the group public key is the same.
+	others, err := coreDKG.RecoverResharedPublicKeyShares(
+		oldIDs[1:], dealerShares[1:], oldThreshold)
+	if err != nil {
+		t.Fatalf("recover reshared public key shares error: %v", err)
+	}
+	if !bytes.Equal(others.GroupPublicKey().Bytes(), gpk.Bytes()) {
+		t.Fatalf("group public key changed by dealers")
+	}
+	// Shares recovered from the same dealers sign for the old group.
+	hash := coreCommon.NewRandomHash()
+	var sigs []coreDKG.PartialSignature
+	for j, id := range newIDs[:newThreshold] {
+		prv, err := received[j].RecoverResharedPrivateKey(
+			oldIDs, oldThreshold)
+		if err != nil {
+			t.Fatalf("recover reshared private key error: %v", err)
+		}
+		if ok, err := pubs.VerifyPrvShare(id, prv); err != nil || !ok {
+			t.Fatalf("reshared private key mismatch: %v", err)
+		}
+		sig, err := prv.Sign(hash)
+		if err != nil {
+			t.Fatalf("sign error: %v", err)
+		}
+		sigs = append(sigs, coreDKG.PartialSignature(sig))
+	}
+	sig, err := coreDKG.RecoverSignature(sigs, newIDs[:newThreshold])
+	if err != nil {
+		t.Fatalf("recover signature error: %v", err)
+	}
+	if !gpk.VerifySignature(hash, sig) {
+		t.Fatalf("signature of new committee is not verified by old group")
+	}
+
+	if _, err := received[0].RecoverResharedPrivateKey(
+		oldIDs[:oldThreshold-1],
+		oldThreshold); err != coreDKG.ErrNotEnoughDealers {
+		t.Fatalf("unexpected error of too few dealers: %v", err)
+	}
+	if _, err := received[0].RecoverResharedPrivateKey(newIDs,
+		oldThreshold); err != coreDKG.ErrShareNotFound {
+		t.Fatalf("unexpected error of unknown dealers: %v", err)
+	}
+	if _, err := coreDKG.RecoverResharedPublicKeyShares(oldIDs,
+		dealerShares[1:], oldThreshold); err != coreDKG.ErrDealerCountMismatch {
+		t.Fatalf("unexpected error of mismatched dealers: %v", err)
+	}
+	_, mismatched, err := coreDKG.NewResharePrivateKeyShares(
+		coreDKG.NewPrivateKey(), newThreshold+1)
+	if err != nil {
+		t.Fatalf("new reshare private key shares error: %v", err)
+	}
+	if _, err := coreDKG.RecoverResharedPublicKeyShares(oldIDs,
+		append(dealerShares[:len(dealerShares)-1], mismatched),
+		oldThreshold); err != coreDKG.ErrReshareThresholdMismatch {
+		t.Fatalf("unexpected error of mismatched threshold: %v", err)
+	}
+	if _, _, err := coreDKG.NewResharePrivateKeyShares(
+		coreDKG.NewPrivateKey(), 0); err != coreDKG.ErrInvalidThreshold {
+		t.Fatalf("unexpected error of invalid threshold: %v", err)
+	}
+}
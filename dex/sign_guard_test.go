@@ -0,0 +1,171 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"testing"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	coreDb "github.com/dexon-foundation/dexon-consensus/core/db"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+	coreUtils "github.com/dexon-foundation/dexon-consensus/core/utils"
+)
+
+// newTestGuardedSigner returns a signer of a new key guarded by watermarks
+// persisted in 'dbInst'.
+func newTestGuardedSigner(t *testing.T, dbInst coreDb.Database) (
+	*coreUtils.Signer, *coreUtils.SignGuard) {
+	prvKey, err := coreEcdsa.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("new private key error: %v", err)
+	}
+	guard, err := coreUtils.NewSignGuard(dbInst.(coreDb.SignGuardStore))
+	if err != nil {
+		t.Fatalf("new sign guard error: %v", err)
+	}
+	if err := guard.SetVoteWatermarkStore(
+		dbInst.(coreDb.VoteWatermarkStore)); err != nil {
+		t.Fatalf("set vote watermark store error: %v", err)
+	}
+	signer := coreUtils.NewSigner(prvKey)
+	signer.SetSignGuard(guard)
+	return signer, guard
+}
+
+func newTestGuardedVote(voteType coreTypes.VoteType, pos coreTypes.Position,
+	period uint64) *coreTypes.Vote {
+	v := coreTypes.NewVote(voteType, coreCommon.NewRandomHash(), period)
+	v.Position = pos
+	return v
+}
+
+func TestSignGuardDoubleSign(t *testing.T) {
+	dbInst, err := coreDb.NewMemBackedDB()
+	if err != nil {
+		t.Fatalf("new db error: %v", err)
+	}
+	signer, guard := newTestGuardedSigner(t, dbInst)
+	pos := coreTypes.Position{Height: 10}
+	v := newTestGuardedVote(coreTypes.VoteCom, pos, 1)
+	if err := signer.SignVote(v); err != nil {
+		t.Fatalf("sign vote error: %v", err)
+	}
+	// Signing the same vote again is allowed.
+	again := *v
+	if err := signer.SignVote(&again); err != nil {
+		t.Fatalf("sign the same vote again error: %v", err)
+	}
+	// Another block in the same period is refused.
+	conflict := newTestGuardedVote(coreTypes.VoteCom, pos, 1)
+	if err := signer.SignVote(conflict); err != coreUtils.ErrDoubleSign {
+		t.Fatalf("unexpected error of double signing: %v", err)
+	}
+	if len(conflict.Signature.Signature) != 0 {
+		t.Fatalf("conflicting vote is signed")
+	}
+	// Votes of other types are guarded separately.
+	if err := signer.SignVote(
+		newTestGuardedVote(coreTypes.VotePreCom, pos, 1)); err != nil {
+		t.Fatalf("sign vote of another type error: %v", err)
+	}
+	// A newer period is allowed, an older one is refused afterward.
+	if err := signer.SignVote(
+		newTestGuardedVote(coreTypes.VoteCom, pos, 2)); err != nil {
+		t.Fatalf("sign vote of newer period error: %v", err)
+	}
+	if err := signer.SignVote(newTestGuardedVote(
+		coreTypes.VoteCom, pos, 1)); err != coreUtils.ErrSignRegression {
+		t.Fatalf("unexpected error of older period: %v", err)
+	}
+	if err := signer.SignVote(newTestGuardedVote(coreTypes.VoteCom,
+		coreTypes.Position{Height: 9}, 5)); err != coreUtils.ErrSignRegression {
+		t.Fatalf("unexpected error of older position: %v", err)
+	}
+	mark, exist := guard.Watermark(coreTypes.VoteCom)
+	if !exist {
+		t.Fatalf("watermark not found")
+	}
+	if mark.Position != pos || mark.Period != 2 {
+		t.Fatalf("unexpected watermark: %v %d", mark.Position, mark.Period)
+	}
+}
+
+func TestSignGuardPersistence(t *testing.T) {
+	dbInst, err := coreDb.NewMemBackedDB()
+	if err != nil {
+		t.Fatalf("new db error: %v", err)
+	}
+	signer, _ := newTestGuardedSigner(t, dbInst)
+	pos := coreTypes.Position{Height: 10}
+	v := newTestGuardedVote(coreTypes.VoteCom, pos, 1)
+	if err := signer.SignVote(v); err != nil {
+		t.Fatalf("sign vote error: %v", err)
+	}
+	// A restarted node, even with a different key, keeps refusing to sign
+	// another block in the same period.
+	signer, _ = newTestGuardedSigner(t, dbInst)
+	if err := signer.SignVote(newTestGuardedVote(
+		coreTypes.VoteCom, pos, 1)); err != coreUtils.ErrDoubleSign {
+		t.Fatalf("unexpected error of double signing after restart: %v", err)
+	}
+	again := *v
+	if err := signer.SignVote(&again); err != nil {
+		t.Fatalf("sign the same vote after restart error: %v", err)
+	}
+}
+
+func TestSignGuardVoteWatermark(t *testing.T) {
+	dbInst, err := coreDb.NewMemBackedDB()
+	if err != nil {
+		t.Fatalf("new db error: %v", err)
+	}
+	signer, guard := newTestGuardedSigner(t, dbInst)
+	if _, exist := guard.VoteWatermark(); exist {
+		t.Fatalf("vote watermark exists before raised")
+	}
+	if err := guard.RaiseVoteWatermark(
+		coreTypes.Position{Height: 10}); err != nil {
+		t.Fatalf("raise vote watermark error: %v", err)
+	}
+	// Lowering the watermark is a no-op.
+	if err := guard.RaiseVoteWatermark(
+		coreTypes.Position{Height: 5}); err != nil {
+		t.Fatalf("lower vote watermark error: %v", err)
+	}
+	if mark, exist := guard.VoteWatermark(); !exist || mark.Height != 10 {
+		t.Fatalf("unexpected vote watermark: %v %v", mark, exist)
+	}
+	for _, height := range []uint64{5, 10} {
+		err := signer.SignVote(newTestGuardedVote(coreTypes.VoteCom,
+			coreTypes.Position{Height: height}, 0))
+		if err != coreUtils.ErrVoteBelowWatermark {
+			t.Fatalf("unexpected error of vote at height %d: %v", height, err)
+		}
+	}
+	if err := signer.SignVote(newTestGuardedVote(coreTypes.VoteCom,
+		coreTypes.Position{Height: 11}, 0)); err != nil {
+		t.Fatalf("sign vote above watermark error: %v", err)
+	}
+	// The watermark is loaded again after restarts.
+	_, guard = newTestGuardedSigner(t, dbInst)
+	if mark, exist := guard.VoteWatermark(); !exist || mark.Height != 10 {
+		t.Fatalf("unexpected vote watermark after restart: %v %v",
+			mark, exist)
+	}
+}
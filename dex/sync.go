@@ -152,11 +152,11 @@ func (pm *ProtocolManager) syncer() {
 			if pm.peers.Len() < minDesiredPeerCount {
 				break
 			}
-			go pm.synchronise(pm.peers.BestPeer(), false)
+			go pm.synchronise(pm.peers.BestPeerWithCapabilities(capArchive), false)
 
 		case <-forceSync.C:
 			// Force a sync even if not enough peers are present
-			go pm.synchronise(pm.peers.BestPeer(), false)
+			go pm.synchronise(pm.peers.BestPeerWithCapabilities(capArchive), false)
 
 		case <-pm.noMorePeers:
 			return
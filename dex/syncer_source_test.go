@@ -0,0 +1,208 @@
+// Copyright 2018 The dexon-consensus Authors
+// This file is part of the dexon-consensus library.
+//
+// The dexon-consensus library is free software: you can redistribute it
+// and/or modify it under the terms of the GNU Lesser General Public License as
+// published by the Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+//
+// The dexon-consensus library is distributed in the hope that it will be
+// useful, but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser
+// General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dexon-consensus library. If not, see
+// <http://www.gnu.org/licenses/>.
+
+package dex
+
+import (
+	"context"
+
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coreCommon "github.com/dexon-foundation/dexon-consensus/common"
+	coreEcdsa "github.com/dexon-foundation/dexon-consensus/core/crypto/ecdsa"
+	coreDb "github.com/dexon-foundation/dexon-consensus/core/db"
+	"github.com/dexon-foundation/dexon-consensus/core/syncer"
+	"github.com/dexon-foundation/dexon-consensus/core/test"
+	coreTypes "github.com/dexon-foundation/dexon-consensus/core/types"
+)
+
+// writeTestSnapshot writes 'blocks' to a snapshot file in 'dir'.
+func writeTestSnapshot(t *testing.T, dir string,
+	blocks []*coreTypes.Block) string {
+	path := filepath.Join(dir, "snapshot")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create snapshot error: %v", err)
+	}
+	defer f.Close()
+	if err := syncer.WriteSnapshot(f, blocks); err != nil {
+		t.Fatalf("write snapshot error: %v", err)
+	}
+	return path
+}
+
+func checkTestSyncedHeights(t *testing.T, blocks []*coreTypes.Block,
+	from, to uint64) {
+	if uint64(len(blocks)) != to-from+1 {
+		t.Fatalf("unexpected block count: %d", len(blocks))
+	}
+	for i, b := range blocks {
+		if b.Position.Height != from+uint64(i) {
+			t.Fatalf("unexpected height: %d", b.Position.Height)
+		}
+	}
+}
+
+func TestSyncFileAndHTTPSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dex-sync-source")
+	if err != nil {
+		t.Fatalf("create temp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	var blocks []*coreTypes.Block
+	for h := coreTypes.GenesisHeight; h <= 10; h++ {
+		blocks = append(blocks, &coreTypes.Block{
+			Hash:     coreCommon.NewRandomHash(),
+			Position: coreTypes.Position{Height: h},
+		})
+	}
+	path := writeTestSnapshot(t, dir, blocks)
+	ctx := context.Background()
+
+	source, err := syncer.NewFileSource(path)
+	if err != nil {
+		t.Fatalf("new file source error: %v", err)
+	}
+	defer source.Close()
+	fetched, err := source.FetchBlocks(ctx, 3, 4)
+	if err != nil {
+		t.Fatalf("fetch blocks error: %v", err)
+	}
+	checkTestSyncedHeights(t, fetched, 3, 6)
+	if fetched[0].Hash != blocks[2].Hash {
+		t.Fatalf("fetched block mismatch")
+	}
+	// Blocks are read forward only.
+	if fetched, err = source.FetchBlocks(ctx, 1, 4); err != nil {
+		t.Fatalf("fetch blocks error: %v", err)
+	}
+	if len(fetched) != 0 {
+		t.Fatalf("blocks read are fetched again: %d", len(fetched))
+	}
+
+	served, err := syncer.NewFileSource(path)
+	if err != nil {
+		t.Fatalf("new file source error: %v", err)
+	}
+	defer served.Close()
+	server := httptest.NewServer(syncer.SyncSourceHandler(served))
+	defer server.Close()
+	remote := syncer.NewHTTPSource(server.URL, nil)
+	if fetched, err = remote.FetchBlocks(ctx, 1, 4); err != nil {
+		t.Fatalf("fetch blocks from http error: %v", err)
+	}
+	checkTestSyncedHeights(t, fetched, 1, 4)
+	// Fewer blocks are returned when no more are available.
+	if fetched, err = remote.FetchBlocks(ctx, 5, 40); err != nil {
+		t.Fatalf("fetch blocks from http error: %v", err)
+	}
+	checkTestSyncedHeights(t, fetched, 5, 10)
+
+	resp, err := http.Get(server.URL + "?from=1&count=0")
+	if err != nil {
+		t.Fatalf("http get error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status of invalid count: %s", resp.Status)
+	}
+}
+
+func TestSyncFromSnapshot(t *testing.T) {
+	c, node := newTestCoreCluster(t, 130)
+	c.Stop()
+	var blocks []*coreTypes.Block
+	for _, d := range node.Delivered() {
+		b, err := node.DB.GetBlock(d.Hash)
+		if err != nil {
+			t.Fatalf("get block error: %v", err)
+		}
+		blocks = append(blocks, &b)
+	}
+	last := blocks[len(blocks)-1]
+	dir, err := ioutil.TempDir("", "dex-sync-snapshot")
+	if err != nil {
+		t.Fatalf("create temp dir error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	hub := test.NewHub()
+	defer hub.Close()
+
+	syncFrom := func(blocks []*coreTypes.Block) (coreDb.Database, error) {
+		prvKey, err := coreEcdsa.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("new private key error: %v", err)
+		}
+		dbInst, err := coreDb.NewMemBackedDB()
+		if err != nil {
+			t.Fatalf("new db error: %v", err)
+		}
+		source, err := syncer.NewFileSource(
+			writeTestSnapshot(t, dir, blocks))
+		if err != nil {
+			t.Fatalf("new file source error: %v", err)
+		}
+		defer source.Close()
+		con := syncer.NewConsensus(0, time.Now().UTC(), test.NewScriptedApp(),
+			c.Governance(), dbInst,
+			hub.NewNetwork(coreTypes.NewNodeID(prvKey.PublicKey())), prvKey,
+			&coreCommon.NullLogger{})
+		_, err = con.SyncFrom(context.Background(), source, 16)
+		_, height := dbInst.GetCompactionChainTipInfo()
+		con.ForceSync(coreTypes.Position{Height: height}, false)
+		return dbInst, err
+	}
+
+	dbInst, err := syncFrom(blocks)
+	if err != nil {
+		t.Fatalf("sync from snapshot error: %v", err)
+	}
+	if hash, height := dbInst.GetCompactionChainTipInfo(); hash != last.Hash ||
+		height != last.Position.Height {
+		t.Fatalf("unexpected compaction chain tip: %v %d", hash, height)
+	}
+
+	// The randomness of another block in the same round.
+	var tampered, other *coreTypes.Block
+	for _, b := range blocks {
+		if b.Position.Round != 1 {
+			continue
+		}
+		if tampered == nil {
+			tampered = b
+		} else {
+			other = b
+			break
+		}
+	}
+	tampered.Randomness = other.Randomness
+	if dbInst, err = syncFrom(blocks); err !=
+		syncer.ErrSyncBlockIncorrectRandomness {
+		t.Fatalf("unexpected error of tampered randomness: %v", err)
+	}
+	// Blocks before the tampered one are synced.
+	if _, height := dbInst.GetCompactionChainTipInfo(); height >=
+		tampered.Position.Height {
+		t.Fatalf("tampered block is synced: %d", height)
+	}
+}
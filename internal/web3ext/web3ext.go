@@ -174,6 +174,18 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'verifyHistory',
+			call: 'admin_verifyHistory',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'rotateNodeKey',
+			call: 'admin_rotateNodeKey',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -233,6 +245,10 @@ web3._extend({
 			name: 'notaryInfo',
 			getter: 'admin_notaryInfo'
 		}),
+		new web3._extend.Property({
+			name: 'historyVerification',
+			getter: 'admin_historyVerification'
+		}),
 	]
 });
 `
@@ -477,6 +493,22 @@ web3._extend({
 			call: 'eth_chainId',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'estimateRoundTime',
+			call: 'eth_estimateRoundTime',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'estimateHeightTime',
+			call: 'eth_estimateHeightTime',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getFinalityProof',
+			call: 'eth_getFinalityProof',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'eth_sign',
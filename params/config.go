@@ -21,6 +21,7 @@ import (
 	"math/big"
 
 	"github.com/dexon-foundation/dexon/common"
+	"github.com/dexon-foundation/dexon/common/hexutil"
 	"github.com/dexon-foundation/dexon/common/math"
 )
 
@@ -232,18 +233,18 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, nil, nil, nil}
 
-	AllDexconProtocolChanges = &ChainConfig{big.NewInt(1337), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(DexconConfig), new(RecoveryConfig)}
+	AllDexconProtocolChanges = &ChainConfig{big.NewInt(1337), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(DexconConfig), new(RecoveryConfig), nil, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), 0, big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 
 	// Ethereum MainnetChainConfig is the chain parameters to run a node on the main network.
@@ -324,6 +325,19 @@ type ChainConfig struct {
 
 	// Dexcon Recovery
 	Recovery *RecoveryConfig `json:"recovery,omitempty"`
+
+	// CanonicalEncodingBlock activates rejecting consensus messages not in
+	// canonical RLP encoding (nil = no fork, 0 = already activated).
+	CanonicalEncodingBlock *big.Int `json:"canonicalEncodingBlock,omitempty"`
+
+	// BlockExtensionBlock activates the extension fields of consensus blocks,
+	// i.e. fee summaries and merkle roots of previous rounds (nil = no fork,
+	// 0 = already activated).
+	BlockExtensionBlock *big.Int `json:"blockExtensionBlock,omitempty"`
+
+	// ManualFinalization is the operator set authorizing manual finalization
+	// of stuck positions (nil = disabled).
+	ManualFinalization *ManualFinalizationConfig `json:"manualFinalization,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -405,6 +419,14 @@ type RecoveryConfig struct {
 	Confirmation int            `json:"confirmation"`
 }
 
+// ManualFinalizationConfig is the operator set authorizing manual
+// finalization, a finalization should be signed by at least Threshold of
+// Operators, which are public keys of consensus.
+type ManualFinalizationConfig struct {
+	Operators []hexutil.Bytes `json:"operators"`
+	Threshold int             `json:"threshold"`
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -478,6 +500,18 @@ func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
 }
 
+// IsCanonicalEncoding returns whether num is either equal to the canonical
+// encoding fork block or greater.
+func (c *ChainConfig) IsCanonicalEncoding(num *big.Int) bool {
+	return isForked(c.CanonicalEncodingBlock, num)
+}
+
+// IsBlockExtension returns whether num is either equal to the block extension
+// fork block or greater.
+func (c *ChainConfig) IsBlockExtension(num *big.Int) bool {
+	return isForked(c.BlockExtensionBlock, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -549,6 +583,12 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.CanonicalEncodingBlock, newcfg.CanonicalEncodingBlock, head) {
+		return newCompatError("canonical encoding fork block", c.CanonicalEncodingBlock, newcfg.CanonicalEncodingBlock)
+	}
+	if isForkIncompatible(c.BlockExtensionBlock, newcfg.BlockExtensionBlock, head) {
+		return newCompatError("block extension fork block", c.BlockExtensionBlock, newcfg.BlockExtensionBlock)
+	}
 	return nil
 }
 